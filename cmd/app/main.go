@@ -2,21 +2,63 @@ package main
 
 import (
 	"context"
-	//"log"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/DmitriySama/teammate_search/config"
 	"github.com/DmitriySama/teammate_search/internal/bootstrap"
 )
 
 func main() {
-	cfg, _ := config.LoadConfig()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Некорректная конфигурация:\n%v", err)
+	}
+	logger, err := bootstrap.InitLogger(cfg)
+	if err != nil {
+		log.Fatalf("Ошибка настройки логирования: %v", err)
+	}
 
+	ctx, stop := signal.NotifyContext(logger.WithContext(context.Background()), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	producer := bootstrap.InitProducers(cfg)
+	// Ресурсы закрываются в обратном порядке: Redis, БД, Kafka
+	lifecycle := bootstrap.NewLifecycle(logger)
 
-	storage:= bootstrap.InitPGStorage(cfg, producer)
-	cache := bootstrap.InitCache(cfg)
-	service := bootstrap.InitTSService(storage, cache)
-	api := bootstrap.InitRegistryAPI(service, cfg.ServiceName, storage)
-	bootstrap.AppRun(context.Background(), cfg, api)
+	producer := bootstrap.InitProducers(cfg, logger)
+	lifecycle.OnStop("Kafka producer", producer.Close)
+
+	storage:= bootstrap.InitPGStorage(cfg, logger)
+	lifecycle.OnStop("PostgreSQL", storage.Close)
+
+	redisClient := bootstrap.InitRedis(cfg, logger)
+	if redisClient != nil {
+		lifecycle.OnStop("Redis", redisClient.Close)
+	}
+	cache := bootstrap.InitCache(cfg, redisClient)
+	sessions := bootstrap.InitSessions(cfg, redisClient, logger)
+	service := bootstrap.InitTSService(cfg, storage, cache)
+	checker := bootstrap.InitHealth(cfg, storage, cache, logger)
+	api := bootstrap.InitRegistryAPI(service, cfg.ServiceName, storage, sessions, checker, logger)
+
+	relay := bootstrap.InitOutboxRelay(cfg, storage, producer)
+	lifecycle.Go("outbox relay", relay.Run)
+	popularity := bootstrap.InitPopularityConsumer(cfg, storage, logger)
+	lifecycle.Go("popularity consumer", popularity.Run)
+	trends := bootstrap.InitTrendsConsumer(cfg, storage, logger)
+	lifecycle.Go("trends consumer", trends.Run)
+
+	runErr := bootstrap.AppRun(ctx, cfg, api, checker)
+	if runErr != nil {
+		logger.Error().Err(runErr).Msg("Сервер остановлен с ошибкой")
+	}
+
+	lifecycle.Shutdown(time.Duration(cfg.ShutdownTimeoutSeconds) * time.Second)
+	logger.Info().Msg("Приложение остановлено")
+	if runErr != nil {
+		os.Exit(1)
+	}
 }
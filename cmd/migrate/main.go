@@ -0,0 +1,69 @@
+// Команда migrate управляет схемой БД встроенными миграциями:
+//
+//	migrate               применить все новые миграции
+//	migrate -down 1       откатить последнюю миграцию
+//	migrate -baseline 6   отметить миграции 1..6 применёнными, если версия базы из initdb
+//	                      определилась автоматически неверно (см. README)
+//	migrate -status       показать текущую версию схемы
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/DmitriySama/teammate_search/config"
+	"github.com/DmitriySama/teammate_search/internal/logging"
+	"github.com/DmitriySama/teammate_search/internal/storage/pgstorage"
+)
+
+func main() {
+	down := flag.Int("down", 0, "откатить N последних миграций")
+	baseline := flag.Int("baseline", 0, "отметить миграции до указанной версии применёнными, не выполняя их")
+	status := flag.Bool("status", false, "показать текущую версию схемы")
+	loader := config.NewLoader(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := loader.Load(os.Getenv)
+	if err != nil {
+		log.Fatalf("Некорректная конфигурация:\n%v", err)
+	}
+	logger, err := logging.New(cfg.Log, os.Stderr)
+	if err != nil {
+		log.Fatalf("Ошибка настройки логирования: %v", err)
+	}
+	ctx := logger.WithContext(context.Background())
+
+	storage, err := pgstorage.InitDB(ctx, cfg.Database, cfg.Topics)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Ошибка подключения к БД")
+	}
+	defer storage.Close()
+
+	switch {
+	case *status:
+	case *baseline > 0:
+		if err := storage.MigrateBaseline(ctx, *baseline); err != nil {
+			logger.Fatal().Err(err).Msg("Ошибка baseline")
+		}
+	case *down > 0:
+		count, err := storage.MigrateDown(ctx, *down)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Ошибка отката миграций")
+		}
+		logger.Info().Int("count", count).Msg("Откачено миграций")
+	default:
+		count, err := storage.MigrateUp(ctx)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Ошибка применения миграций")
+		}
+		logger.Info().Int("count", count).Msg("Применено миграций")
+	}
+
+	version, err := storage.SchemaVersion(ctx)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Ошибка чтения версии схемы")
+	}
+	logger.Info().Int("version", version).Msg("Версия схемы")
+}
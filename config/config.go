@@ -1,18 +1,29 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
 	"fmt"
-	"gopkg.in/yaml.v3"
 	"os"
 )
 
 type Config struct {
 	ServiceName string         `yaml:"serviceName"`
 	Port        int            `yaml:"port"`
+	// ShutdownTimeoutSeconds сколько ждать завершения запросов и воркеров при остановке
+	ShutdownTimeoutSeconds int `yaml:"shutdownTimeoutSeconds"`
 	Database    DatabaseConfig `yaml:"database"`
 	Kafka       KafkaConfig    `yaml:"kafka"`
 	Topics      TopicsConfig   `yaml:"topics"`
 	Redis       RedisConfig    `yaml:"redis"`
+	Session     SessionConfig  `yaml:"session"`
+	Popularity  PopularityConfig `yaml:"popularity"`
+	Trends      TrendsConfig   `yaml:"trends"`
+	Recommendations RecommendationsConfig `yaml:"recommendations"`
+	Outbox      OutboxConfig   `yaml:"outbox"`
+	Health      HealthConfig   `yaml:"health"`
+	Log         LogConfig      `yaml:"log"`
 }
 
 type DatabaseConfig struct {
@@ -23,25 +34,18 @@ type DatabaseConfig struct {
 	Username string `yaml:"username"`
 	Password string `yaml:"password"`
 	ConnURL  string `yaml:"connURL"`
+	// AutoMigrate применять встроенные миграции при старте приложения
+	AutoMigrate bool `yaml:"autoMigrate"`
 }
 
 
+// LoadConfig загружает конфигурацию из значений по умолчанию, YAML ($CONFIG_PATH или -config),
+// переменных окружения и флагов командной строки процесса
 func LoadConfig() (*Config, error) {
-	path := os.Getenv("CONFIG_PATH")
-	file, err := os.Open(path)
-    if err != nil {
-        return nil, fmt.Errorf("не удалось открыть файл конфигурации: %w", err)
-    }
-    defer file.Close()
-    
-    // Читаем содержимое
-    var config Config
-    decoder := yaml.NewDecoder(file)
-    if err := decoder.Decode(&config); err != nil {
-        return nil, fmt.Errorf("не удалось декодировать YAML: %w", err)
-    }
-    
-    return &config, nil
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	loader := NewLoader(fs)
+	fs.Parse(os.Args[1:])
+	return loader.Load(os.Getenv)
 }
 
 
@@ -51,7 +55,7 @@ func (d *DatabaseConfig) DatabaseURL() string {
     }
     
     // Правильный формат для lib/pq
-    return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s ssl=disable",
+    return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
         d.Host, d.Port, d.Username, d.Password, d.DBName)
 }
 
@@ -66,18 +70,141 @@ type KafkaConfig struct {
 	Port            int      `yaml:"port"`
 	GroupID         string   `yaml:"groupId"`
 	MaxMessageBytes int      `yaml:"maxMessageBytes"`
+	TLS             TLSConfig  `yaml:"tls"`
+	SASL            SASLConfig `yaml:"sasl"`
+}
+
+// SASL-механизмы аутентификации в Kafka
+const (
+	SASLPlain       = "plain"
+	SASLScramSHA256 = "scram-sha-256"
+	SASLScramSHA512 = "scram-sha-512"
+)
+
+// SASLConfig аутентификация в Kafka, пустой Mechanism — без SASL
+type SASLConfig struct {
+	Mechanism string `yaml:"mechanism"`
+	Username  string `yaml:"username"`
+	Password  string `yaml:"password"`
+}
+
+// TLSConfig настройки TLS-клиента. CAFile — корневой сертификат сервера,
+// CertFile/KeyFile — клиентский сертификат для mTLS
+type TLSConfig struct {
+	Enabled            bool   `yaml:"enabled"`
+	CAFile             string `yaml:"caFile"`
+	CertFile           string `yaml:"certFile"`
+	KeyFile            string `yaml:"keyFile"`
+	ServerName         string `yaml:"serverName"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify"`
+}
+
+// ClientConfig собирает *tls.Config, nil — если TLS выключен
+func (t TLSConfig) ClientConfig() (*tls.Config, error) {
+	if !t.Enabled {
+		return nil, nil
+	}
+
+	tlsCfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось прочитать CA-сертификат: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("в файле %s нет PEM-сертификатов", t.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось загрузить клиентский сертификат: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	return tlsCfg, nil
 }
 
 type TopicsConfig struct {
 	FilterData        string `yaml:"filterData"`
-	UserPopylarity        string `yaml:"userPopylarity"`
+	UserPopularity        string `yaml:"userPopularity"`
 	UpdateUserData        string `yaml:"UpdateUserData"`
+	TeammateRequests      string `yaml:"teammateRequests"`
 }
 
 type RedisConfig struct {
 	Host string `yaml:"host"`
 	Port int    `yaml:"port"`
 	Username string    `yaml:"username"`
+	Password string    `yaml:"password"`
 	DB   int    `yaml:"db"`
 	TTL  int    `yaml:"ttlSeconds"`
-}
\ No newline at end of file
+	TLS  TLSConfig `yaml:"tls"`
+}
+
+type SessionConfig struct {
+	TTL int `yaml:"ttlSeconds"`
+	// SecureCookie отправлять cookie сессии только по HTTPS; отключается для локальной разработки по HTTP
+	SecureCookie bool `yaml:"secureCookie"`
+}
+
+// PopularityConfig настройки подсчёта популярности из событий user.popularity
+type PopularityConfig struct {
+	HalfLifeHours        int `yaml:"halfLifeHours"`
+	BatchSize            int `yaml:"batchSize"`
+	FlushIntervalSeconds int `yaml:"flushIntervalSeconds"`
+	DecayIntervalMinutes int `yaml:"decayIntervalMinutes"`
+}
+
+// TrendsConfig настройки сбора аналитики поиска из событий filter.data
+type TrendsConfig struct {
+	BatchSize            int `yaml:"batchSize"`
+	FlushIntervalSeconds int `yaml:"flushIntervalSeconds"`
+}
+
+// RecommendationsConfig веса оценки совместимости в рекомендациях тиммейтов.
+// Веса относительные: итоговая оценка делится на их сумму
+type RecommendationsConfig struct {
+	GameWeight       float64 `yaml:"gameWeight"`
+	GenreWeight      float64 `yaml:"genreWeight"`
+	LanguageWeight   float64 `yaml:"languageWeight"`
+	AppWeight        float64 `yaml:"appWeight"`
+	AgeWeight        float64 `yaml:"ageWeight"`
+	PopularityWeight float64 `yaml:"popularityWeight"`
+	// AgeToleranceYears разница в возрасте, при которой вклад возраста падает до нуля
+	AgeToleranceYears int `yaml:"ageToleranceYears"`
+	// Candidates сколько кандидатов оценивается за один запрос
+	Candidates int `yaml:"candidates"`
+	Limit      int `yaml:"limit"`
+}
+
+// HealthConfig настройки проверки зависимостей в /readyz
+type HealthConfig struct {
+	TimeoutMillis int `yaml:"timeoutMillis"`
+	// ShutdownDelayMillis сколько отдавать 503 в /readyz перед остановкой HTTP-сервера,
+	// чтобы балансировщик успел исключить экземпляр
+	ShutdownDelayMillis int `yaml:"shutdownDelayMillis"`
+}
+
+// LogConfig уровень (trace, debug, info, warn, error) и формат (json, console) логов
+type LogConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+// OutboxConfig настройки релея, отправляющего события из таблицы outbox в Kafka
+type OutboxConfig struct {
+	BatchSize             int `yaml:"batchSize"`
+	PollIntervalMillis    int `yaml:"pollIntervalMillis"`
+	PublishTimeoutSeconds int `yaml:"publishTimeoutSeconds"`
+	MinBackoffSeconds     int `yaml:"minBackoffSeconds"`
+	MaxBackoffSeconds     int `yaml:"maxBackoffSeconds"`
+	// LeaseSeconds на сколько захваченные релеем события скрываются от других релеев
+	LeaseSeconds int `yaml:"leaseSeconds"`
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigSuite struct {
+	suite.Suite
+	dir string
+	env map[string]string
+}
+
+func (s *ConfigSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.env = map[string]string{}
+}
+
+func TestConfigSuite(t *testing.T) {
+	suite.Run(t, new(ConfigSuite))
+}
+
+func (s *ConfigSuite) writeYAML(content string) string {
+	path := filepath.Join(s.dir, "config.yml")
+	s.Require().NoError(os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func (s *ConfigSuite) load(args ...string) (*Config, error) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	loader := NewLoader(fs)
+	s.Require().NoError(fs.Parse(args))
+	return loader.Load(func(key string) string { return s.env[key] })
+}
+
+const validYAML = `
+port: 3000
+database:
+  host: yaml-db
+  dbname: teammates_data
+  username: teammate_search
+kafka:
+  brokers: [yaml-kafka:9094]
+redis:
+  host: yaml-redis
+`
+
+func (s *ConfigSuite) TestLoad_Layering() {
+	s.env["CONFIG_PATH"] = s.writeYAML(validYAML)
+	s.env["DATABASE_HOST"] = "env-db"
+	s.env["PORT"] = "4000"
+	s.env["KAFKA_BOOTSTRAP_SERVERS"] = "k1:9094, k2:9094"
+
+	cfg, err := s.load("-port", "5000")
+
+	s.Require().NoError(err)
+	s.Equal(5000, cfg.Port)
+	s.Equal("env-db", cfg.Database.Host)
+	s.Equal("yaml-redis", cfg.Redis.Host)
+	s.Equal([]string{"k1:9094", "k2:9094"}, cfg.Kafka.Brokers)
+	// не заданные в YAML значения остаются по умолчанию
+	s.Equal(5432, cfg.Database.Port)
+	s.Equal("filter.data", cfg.Topics.FilterData)
+}
+
+func (s *ConfigSuite) TestLoad_SecureCookieByDefault() {
+	s.env["CONFIG_PATH"] = s.writeYAML(validYAML)
+
+	cfg, err := s.load()
+	s.Require().NoError(err)
+	s.True(cfg.Session.SecureCookie)
+
+	s.env["SESSION_SECURE_COOKIE"] = "false"
+	cfg, err = s.load()
+	s.Require().NoError(err)
+	s.False(cfg.Session.SecureCookie)
+}
+
+func (s *ConfigSuite) TestLoad_ConfigFlagOverridesEnvPath() {
+	s.env["CONFIG_PATH"] = filepath.Join(s.dir, "missing.yml")
+	path := s.writeYAML(validYAML)
+
+	cfg, err := s.load("-config", path)
+
+	s.Require().NoError(err)
+	s.Equal("yaml-db", cfg.Database.Host)
+}
+
+func (s *ConfigSuite) TestLoad_ReportsAllProblems() {
+	s.env["REDIS_PORT"] = "70000"
+
+	_, err := s.load()
+
+	s.Require().Error(err)
+	s.ErrorContains(err, "database.host")
+	s.ErrorContains(err, "kafka.brokers")
+	s.ErrorContains(err, "redis.host")
+	s.ErrorContains(err, "redis.port")
+}
+
+func (s *ConfigSuite) TestLoad_InvalidEnvValue() {
+	s.env["CONFIG_PATH"] = s.writeYAML(validYAML)
+	s.env["REDIS_DB"] = "first"
+
+	_, err := s.load()
+
+	s.ErrorContains(err, "REDIS_DB")
+}
+
+func (s *ConfigSuite) TestLoad_UnknownYAMLField() {
+	s.env["CONFIG_PATH"] = s.writeYAML(validYAML + "topics:\n  userPopylarity: user.popularity\n")
+
+	_, err := s.load()
+
+	s.ErrorContains(err, "userPopylarity")
+}
+
+func (s *ConfigSuite) TestRepositoryConfigIsValid() {
+	s.env["CONFIG_PATH"] = "../config.yml"
+
+	_, err := s.load()
+
+	s.NoError(err)
+}
+
+func (s *ConfigSuite) TestValidate_KafkaSecurity() {
+	s.env["CONFIG_PATH"] = s.writeYAML(validYAML)
+	s.env["KAFKA_SASL_MECHANISM"] = SASLScramSHA512
+	s.env["KAFKA_TLS_ENABLED"] = "true"
+	s.env["KAFKA_TLS_CA_FILE"] = filepath.Join(s.dir, "missing-ca.pem")
+
+	_, err := s.load()
+
+	s.Require().Error(err)
+	s.ErrorContains(err, "kafka.sasl.username")
+	s.ErrorContains(err, "kafka.tls")
+}
+
+func (s *ConfigSuite) TestValidate_UnknownSASLMechanism() {
+	s.env["CONFIG_PATH"] = s.writeYAML(validYAML)
+
+	_, err := s.load("-kafka-sasl-mechanism", "gssapi")
+
+	s.ErrorContains(err, "kafka.sasl.mechanism")
+}
+
+func (s *ConfigSuite) TestValidate_Log() {
+	s.env["CONFIG_PATH"] = s.writeYAML(validYAML)
+	s.env["LOG_LEVEL"] = "verbose"
+
+	_, err := s.load("-log-format", "xml")
+
+	s.Require().Error(err)
+	s.ErrorContains(err, "log.level")
+	s.ErrorContains(err, "log.format")
+}
+
+func (s *ConfigSuite) TestValidate_RecommendationWeights() {
+	s.env["CONFIG_PATH"] = s.writeYAML(validYAML + "recommendations:\n  gameWeight: -1\n  genreWeight: 0\n  languageWeight: 0\n  appWeight: 0\n  ageWeight: 0\n  popularityWeight: 0\n")
+
+	_, err := s.load()
+
+	s.Require().Error(err)
+	s.ErrorContains(err, "recommendations.gameWeight")
+	s.ErrorContains(err, "сумма весов")
+}
+
+func (s *ConfigSuite) TestValidate_OutboxLeaseCoversPublish() {
+	s.env["CONFIG_PATH"] = s.writeYAML(validYAML + "outbox:\n  batchSize: 100\n  pollIntervalMillis: 500\n  publishTimeoutSeconds: 10\n  minBackoffSeconds: 1\n  maxBackoffSeconds: 300\n  leaseSeconds: 10\n")
+
+	_, err := s.load()
+
+	s.ErrorContains(err, "outbox.leaseSeconds")
+}
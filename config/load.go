@@ -0,0 +1,246 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Defaults значения, на которые накладываются YAML, переменные окружения и флаги
+func Defaults() Config {
+	return Config{
+		ServiceName:            "teammate-search",
+		Port:                   3000,
+		ShutdownTimeoutSeconds: 15,
+		Database: DatabaseConfig{
+			Port: 5432,
+		},
+		Kafka: KafkaConfig{
+			GroupID:         "teammate-search",
+			MaxMessageBytes: 1048588,
+		},
+		Topics: TopicsConfig{
+			FilterData:       "filter.data",
+			UserPopularity:   "user.popularity",
+			UpdateUserData:   "update.user.data",
+			TeammateRequests: "teammate.requests",
+		},
+		Redis: RedisConfig{
+			Port: 6379,
+			TTL:  600,
+		},
+		Session: SessionConfig{
+			TTL:          86400,
+			SecureCookie: true,
+		},
+		Popularity: PopularityConfig{
+			HalfLifeHours:        168,
+			BatchSize:            100,
+			FlushIntervalSeconds: 5,
+			DecayIntervalMinutes: 60,
+		},
+		Trends: TrendsConfig{
+			BatchSize:            500,
+			FlushIntervalSeconds: 10,
+		},
+		Recommendations: RecommendationsConfig{
+			GameWeight:        3,
+			GenreWeight:       2,
+			LanguageWeight:    2,
+			AppWeight:         1,
+			AgeWeight:         1,
+			PopularityWeight:  0.5,
+			AgeToleranceYears: 10,
+			Candidates:        500,
+			Limit:             6,
+		},
+		Health: HealthConfig{
+			TimeoutMillis: 2000,
+		},
+		Outbox: OutboxConfig{
+			BatchSize:             100,
+			PollIntervalMillis:    500,
+			PublishTimeoutSeconds: 10,
+			MinBackoffSeconds:     1,
+			MaxBackoffSeconds:     300,
+			LeaseSeconds:          60,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+	}
+}
+
+// override параметр, который можно задать переменной окружения и флагом командной строки
+type override struct {
+	env   string
+	flag  string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+var overrides = []override{
+	{"SERVICE_NAME", "service-name", "имя сервиса", setString(func(c *Config) *string { return &c.ServiceName })},
+	{"PORT", "port", "порт HTTP-сервера", setInt(func(c *Config) *int { return &c.Port })},
+
+	{"DATABASE_HOST", "db-host", "хост PostgreSQL", setString(func(c *Config) *string { return &c.Database.Host })},
+	{"DATABASE_PORT", "db-port", "порт PostgreSQL", setInt(func(c *Config) *int { return &c.Database.Port })},
+	{"DATABASE_NAME", "db-name", "имя базы данных", setString(func(c *Config) *string { return &c.Database.DBName })},
+	{"DATABASE_USER", "db-user", "пользователь PostgreSQL", setString(func(c *Config) *string { return &c.Database.Username })},
+	{"DATABASE_PASSWORD", "db-password", "пароль PostgreSQL", setString(func(c *Config) *string { return &c.Database.Password })},
+	{"DATABASE_AUTO_MIGRATE", "db-auto-migrate", "применять миграции при старте", setBool(func(c *Config) *bool { return &c.Database.AutoMigrate })},
+
+	{"KAFKA_BOOTSTRAP_SERVERS", "kafka-brokers", "брокеры Kafka через запятую", setList(func(c *Config) *[]string { return &c.Kafka.Brokers })},
+	{"KAFKA_TLS_ENABLED", "kafka-tls", "подключаться к Kafka по TLS", setBool(func(c *Config) *bool { return &c.Kafka.TLS.Enabled })},
+	{"KAFKA_TLS_CA_FILE", "kafka-tls-ca", "CA-сертификат Kafka", setString(func(c *Config) *string { return &c.Kafka.TLS.CAFile })},
+	{"KAFKA_SASL_MECHANISM", "kafka-sasl-mechanism", "SASL-механизм Kafka: plain, scram-sha-256, scram-sha-512", setString(func(c *Config) *string { return &c.Kafka.SASL.Mechanism })},
+	{"KAFKA_SASL_USERNAME", "kafka-sasl-username", "пользователь SASL Kafka", setString(func(c *Config) *string { return &c.Kafka.SASL.Username })},
+	{"KAFKA_SASL_PASSWORD", "kafka-sasl-password", "пароль SASL Kafka", setString(func(c *Config) *string { return &c.Kafka.SASL.Password })},
+	{"KAFKA_GROUP_ID", "kafka-group-id", "группа консьюмеров Kafka", setString(func(c *Config) *string { return &c.Kafka.GroupID })},
+	{"FILTER_DATA_TOPIC", "topic-filter-data", "топик параметров поиска", setString(func(c *Config) *string { return &c.Topics.FilterData })},
+	{"USER_POPULARITY_TOPIC", "topic-user-popularity", "топик просмотров анкет", setString(func(c *Config) *string { return &c.Topics.UserPopularity })},
+	{"UPDATE_USER_DATA_TOPIC", "topic-update-user-data", "топик изменений профиля", setString(func(c *Config) *string { return &c.Topics.UpdateUserData })},
+	{"TEAMMATE_REQUESTS_TOPIC", "topic-teammate-requests", "топик заявок в тиммейты", setString(func(c *Config) *string { return &c.Topics.TeammateRequests })},
+
+	{"REDIS_HOST", "redis-host", "хост Redis", setString(func(c *Config) *string { return &c.Redis.Host })},
+	{"REDIS_PORT", "redis-port", "порт Redis", setInt(func(c *Config) *int { return &c.Redis.Port })},
+	{"REDIS_USERNAME", "redis-username", "пользователь Redis", setString(func(c *Config) *string { return &c.Redis.Username })},
+	{"REDIS_PASSWORD", "redis-password", "пароль Redis", setString(func(c *Config) *string { return &c.Redis.Password })},
+	{"REDIS_TLS_ENABLED", "redis-tls", "подключаться к Redis по TLS", setBool(func(c *Config) *bool { return &c.Redis.TLS.Enabled })},
+	{"REDIS_TLS_CA_FILE", "redis-tls-ca", "CA-сертификат Redis", setString(func(c *Config) *string { return &c.Redis.TLS.CAFile })},
+	{"REDIS_DB", "redis-db", "номер базы Redis", setInt(func(c *Config) *int { return &c.Redis.DB })},
+	{"REDIS_TTL_SECONDS", "redis-ttl", "время жизни кеша, секунды", setInt(func(c *Config) *int { return &c.Redis.TTL })},
+
+	{"SESSION_TTL_SECONDS", "session-ttl", "время жизни сессии, секунды", setInt(func(c *Config) *int { return &c.Session.TTL })},
+	{"SESSION_SECURE_COOKIE", "session-secure-cookie", "отправлять cookie сессии только по HTTPS", setBool(func(c *Config) *bool { return &c.Session.SecureCookie })},
+
+	{"LOG_LEVEL", "log-level", "уровень логов: trace, debug, info, warn, error", setString(func(c *Config) *string { return &c.Log.Level })},
+	{"LOG_FORMAT", "log-format", "формат логов: json, console", setString(func(c *Config) *string { return &c.Log.Format })},
+}
+
+func setString(field func(c *Config) *string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func setInt(field func(c *Config) *int) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("ожидается целое число, получено %q", value)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func setBool(field func(c *Config) *bool) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("ожидается true или false, получено %q", value)
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func setList(field func(c *Config) *[]string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*field(c) = items
+		return nil
+	}
+}
+
+// Loader собирает конфигурацию слоями: значения по умолчанию → YAML → переменные окружения → флаги
+type Loader struct {
+	path  *string
+	flags *flag.FlagSet
+	set   map[string]string
+}
+
+// NewLoader регистрирует флаг -config и флаги переопределения параметров в fs.
+// Значения флагов берутся после fs.Parse
+func NewLoader(fs *flag.FlagSet) *Loader {
+	l := &Loader{
+		path:  fs.String("config", "", "путь к YAML-файлу конфигурации (по умолчанию $CONFIG_PATH)"),
+		flags: fs,
+		set:   map[string]string{},
+	}
+	for _, o := range overrides {
+		fs.Func(o.flag, o.usage+" ($"+o.env+")", func(value string) error {
+			l.set[o.flag] = value
+			return nil
+		})
+	}
+	return l
+}
+
+// Load читает конфигурацию и проверяет её. getenv обычно os.Getenv
+func (l *Loader) Load(getenv func(string) string) (*Config, error) {
+	cfg := Defaults()
+
+	path := *l.path
+	if path == "" {
+		path = getenv("CONFIG_PATH")
+	}
+	if path != "" {
+		if err := readYAML(path, &cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	for _, o := range overrides {
+		if value := getenv(o.env); value != "" {
+			if err := o.set(&cfg, value); err != nil {
+				errs = append(errs, fmt.Errorf("переменная %s: %w", o.env, err))
+			}
+		}
+	}
+	for _, o := range overrides {
+		if value, ok := l.set[o.flag]; ok {
+			if err := o.set(&cfg, value); err != nil {
+				errs = append(errs, fmt.Errorf("флаг -%s: %w", o.flag, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func readYAML(path string, cfg *Config) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть файл конфигурации: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("не удалось декодировать YAML: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Validate проверяет конфигурацию и возвращает сразу все найденные ошибки
+func (c *Config) Validate() error {
+	var errs []error
+	require := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	validPort := func(port int) bool { return port > 0 && port <= 65535 }
+
+	require(c.ServiceName != "", "serviceName: не задан")
+	require(validPort(c.Port), "port: некорректный порт %d", c.Port)
+	require(c.ShutdownTimeoutSeconds > 0, "shutdownTimeoutSeconds: должен быть больше 0")
+
+	require(c.Database.Host != "", "database.host: не задан")
+	require(validPort(c.Database.Port), "database.port: некорректный порт %d", c.Database.Port)
+	require(c.Database.DBName != "", "database.dbname: не задан")
+	require(c.Database.Username != "", "database.username: не задан")
+
+	require(len(c.Kafka.Brokers) > 0, "kafka.brokers: не задан ни один брокер")
+	for i, broker := range c.Kafka.Brokers {
+		require(broker != "", "kafka.brokers[%d]: пустой адрес", i)
+	}
+	require(c.Kafka.GroupID != "", "kafka.groupId: не задан")
+	require(c.Kafka.MaxMessageBytes > 0, "kafka.maxMessageBytes: должен быть больше 0")
+	switch c.Kafka.SASL.Mechanism {
+	case "":
+	case SASLPlain, SASLScramSHA256, SASLScramSHA512:
+		require(c.Kafka.SASL.Username != "", "kafka.sasl.username: обязателен для механизма %s", c.Kafka.SASL.Mechanism)
+	default:
+		errs = append(errs, fmt.Errorf("kafka.sasl.mechanism: неизвестный механизм %q", c.Kafka.SASL.Mechanism))
+	}
+	errs = append(errs, validateTLS("kafka.tls", c.Kafka.TLS)...)
+
+	require(c.Topics.FilterData != "", "topics.filterData: не задан")
+	require(c.Topics.UserPopularity != "", "topics.userPopularity: не задан")
+	require(c.Topics.UpdateUserData != "", "topics.UpdateUserData: не задан")
+	require(c.Topics.TeammateRequests != "", "topics.teammateRequests: не задан")
+
+	require(c.Redis.Host != "", "redis.host: не задан")
+	require(validPort(c.Redis.Port), "redis.port: некорректный порт %d", c.Redis.Port)
+	require(c.Redis.DB >= 0, "redis.db: не может быть отрицательным")
+	require(c.Redis.TTL > 0, "redis.ttlSeconds: должен быть больше 0")
+	errs = append(errs, validateTLS("redis.tls", c.Redis.TLS)...)
+
+	require(c.Session.TTL > 0, "session.ttlSeconds: должен быть больше 0")
+
+	require(c.Popularity.HalfLifeHours > 0, "popularity.halfLifeHours: должен быть больше 0")
+	require(c.Popularity.BatchSize > 0, "popularity.batchSize: должен быть больше 0")
+	require(c.Popularity.FlushIntervalSeconds > 0, "popularity.flushIntervalSeconds: должен быть больше 0")
+	require(c.Popularity.DecayIntervalMinutes > 0, "popularity.decayIntervalMinutes: должен быть больше 0")
+
+	require(c.Trends.BatchSize > 0, "trends.batchSize: должен быть больше 0")
+	require(c.Trends.FlushIntervalSeconds > 0, "trends.flushIntervalSeconds: должен быть больше 0")
+
+	rc := c.Recommendations
+	weights := []struct {
+		name  string
+		value float64
+	}{
+		{"gameWeight", rc.GameWeight}, {"genreWeight", rc.GenreWeight}, {"languageWeight", rc.LanguageWeight},
+		{"appWeight", rc.AppWeight}, {"ageWeight", rc.AgeWeight}, {"popularityWeight", rc.PopularityWeight},
+	}
+	var total float64
+	for _, w := range weights {
+		require(w.value >= 0, "recommendations.%s: не может быть отрицательным", w.name)
+		total += w.value
+	}
+	require(total > 0, "recommendations: сумма весов должна быть больше 0")
+	require(rc.AgeToleranceYears > 0, "recommendations.ageToleranceYears: должен быть больше 0")
+	require(rc.Candidates > 0, "recommendations.candidates: должен быть больше 0")
+	require(rc.Limit > 0 && rc.Limit <= rc.Candidates, "recommendations.limit: должен быть от 1 до candidates")
+
+	require(c.Outbox.BatchSize > 0, "outbox.batchSize: должен быть больше 0")
+	require(c.Outbox.PollIntervalMillis > 0, "outbox.pollIntervalMillis: должен быть больше 0")
+	require(c.Outbox.PublishTimeoutSeconds > 0, "outbox.publishTimeoutSeconds: должен быть больше 0")
+	require(c.Outbox.MinBackoffSeconds > 0, "outbox.minBackoffSeconds: должен быть больше 0")
+	require(c.Outbox.MaxBackoffSeconds >= c.Outbox.MinBackoffSeconds, "outbox.maxBackoffSeconds: должен быть не меньше minBackoffSeconds")
+	require(c.Outbox.LeaseSeconds > c.Outbox.PublishTimeoutSeconds, "outbox.leaseSeconds: должен быть больше publishTimeoutSeconds")
+
+	require(c.Health.TimeoutMillis > 0, "health.timeoutMillis: должен быть больше 0")
+	require(c.Health.ShutdownDelayMillis >= 0, "health.shutdownDelayMillis: не может быть отрицательным")
+
+	switch c.Log.Level {
+	case "trace", "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("log.level: неизвестный уровень %q", c.Log.Level))
+	}
+	require(c.Log.Format == "json" || c.Log.Format == "console", "log.format: ожидается json или console, получено %q", c.Log.Format)
+
+	return errors.Join(errs...)
+}
+
+func validateTLS(prefix string, t TLSConfig) []error {
+	if !t.Enabled {
+		return nil
+	}
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return []error{fmt.Errorf("%s: certFile и keyFile задаются вместе", prefix)}
+	}
+	if _, err := t.ClientConfig(); err != nil {
+		return []error{fmt.Errorf("%s: %w", prefix, err)}
+	}
+	return nil
+}
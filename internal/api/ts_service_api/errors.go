@@ -0,0 +1,198 @@
+package ts_service_api
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/DmitriySama/teammate_search/internal/apperr"
+	"github.com/DmitriySama/teammate_search/internal/logging"
+)
+
+// Коды ошибок в ответах JSON API
+const (
+	errCodeBadRequest   = "bad_request"
+	errCodeUnauthorized = "unauthorized"
+	errCodeNotFound     = "not_found"
+	errCodeConflict     = "conflict"
+	errCodeInternal     = "internal"
+)
+
+// errorBody единый формат ошибки JSON API: {"error": {"code": "...", "message": "..."}}
+type errorBody struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Code    string             `json:"code"`
+	Message string             `json:"message"`
+	Fields  apperr.FieldErrors `json:"fields,omitempty"`
+}
+
+// errorStatus HTTP-статус и код JSON-ошибки для вида доменной ошибки
+func errorStatus(kind apperr.Kind) (int, string) {
+	switch kind {
+	case apperr.KindValidation:
+		return http.StatusBadRequest, errCodeBadRequest
+	case apperr.KindUnauthorized:
+		return http.StatusUnauthorized, errCodeUnauthorized
+	case apperr.KindNotFound:
+		return http.StatusNotFound, errCodeNotFound
+	case apperr.KindConflict:
+		return http.StatusConflict, errCodeConflict
+	default:
+		return http.StatusInternalServerError, errCodeInternal
+	}
+}
+
+// logError пишет в лог только внутренние ошибки: остальные — ожидаемый ответ пользователю
+func logError(r *http.Request, err error) {
+	if apperr.KindOf(err) == apperr.KindInternal {
+		zerolog.Ctx(r.Context()).Error().Err(err).Str("path", r.URL.Path).Msg("Ошибка обработки запроса")
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, errorBody{Error: errorDetail{Code: code, Message: message}})
+}
+
+// writeServiceError переводит ошибку сервиса в HTTP-статус и JSON-ошибку
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	logError(r, err)
+	writeErrorJSON(w, err)
+}
+
+func writeErrorJSON(w http.ResponseWriter, err error) {
+	status, code := errorStatus(apperr.KindOf(err))
+	writeJSON(w, status, errorBody{Error: errorDetail{Code: code, Message: apperr.MessageOf(err), Fields: apperr.FieldsOf(err)}})
+}
+
+// errorPage данные шаблона error.html
+type errorPage struct {
+	Status    int
+	Message   string
+	RequestID string
+	BackURL   string
+}
+
+// renderError отвечает HTML-страницей ошибки
+func renderError(w http.ResponseWriter, r *http.Request, err error) {
+	logError(r, err)
+	renderErrorPage(w, r, err)
+}
+
+// renderErrorPage отрисовывает error.html, а если шаблон недоступен — отдаёт текст
+func renderErrorPage(w http.ResponseWriter, r *http.Request, err error) {
+	status, _ := errorStatus(apperr.KindOf(err))
+	page := errorPage{
+		Status:    status,
+		Message:   apperr.MessageOf(err),
+		RequestID: logging.RequestID(r.Context()),
+		BackURL:   "/main/home",
+	}
+	if status == http.StatusUnauthorized {
+		page.BackURL = "/login"
+	}
+
+	tmpl, parseErr := template.ParseFiles(getFrontendPath() + "/error.html")
+	if parseErr != nil {
+		zerolog.Ctx(r.Context()).Error().Err(parseErr).Msg("Ошибка загрузки шаблона страницы ошибки")
+		http.Error(w, page.Message, status)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := tmpl.Execute(w, page); err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Ошибка отрисовки страницы ошибки")
+	}
+}
+
+// render отрисовывает шаблон из каталога фронтенда, ошибки шаблона превращаются в страницу 500
+func render(w http.ResponseWriter, r *http.Request, name string, data any) {
+	renderStatus(w, r, http.StatusOK, name, data)
+}
+
+func renderStatus(w http.ResponseWriter, r *http.Request, status int, name string, data any) {
+	tmpl, err := template.ParseFiles(getFrontendPath() + "/" + name)
+	if err != nil {
+		renderError(w, r, apperr.Internal("не удалось загрузить страницу", err))
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := tmpl.Execute(w, data); err != nil {
+		// Заголовки уже могли уйти клиенту, поэтому ошибка только логируется
+		zerolog.Ctx(r.Context()).Error().Err(err).Str("template", name).Msg("Ошибка отрисовки шаблона")
+	}
+}
+
+// formErrorKey ключ общей ошибки формы, не относящейся к конкретному полю
+const formErrorKey = "form"
+
+// formPage данные шаблона формы: введённые значения и ошибки по полям
+type formPage struct {
+	Values map[string]string
+	Errors apperr.FieldErrors
+}
+
+// formErrors возвращает сообщения для повторного показа формы. ok=false означает,
+// что ошибка не связана с вводом и должна показываться страницей ошибки
+func formErrors(err error) (fields apperr.FieldErrors, ok bool) {
+	switch apperr.KindOf(err) {
+	case apperr.KindValidation, apperr.KindConflict, apperr.KindUnauthorized:
+	default:
+		return nil, false
+	}
+	if fields := apperr.FieldsOf(err); len(fields) > 0 {
+		return fields, true
+	}
+	return apperr.FieldErrors{formErrorKey: apperr.MessageOf(err)}, true
+}
+
+// renderForm повторно показывает форму name с введёнными значениями и ошибками полей
+func renderForm(w http.ResponseWriter, r *http.Request, name string, values map[string]string, err error) {
+	fields, ok := formErrors(err)
+	if !ok {
+		renderError(w, r, err)
+		return
+	}
+	status, _ := errorStatus(apperr.KindOf(err))
+	renderStatus(w, r, status, name, formPage{Values: values, Errors: fields})
+}
+
+// isAPIRequest запросы JSON API получают ошибки в JSON, остальные — HTML-страницу
+func isAPIRequest(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/api/")
+}
+
+// recoverer перехватывает панику обработчика, пишет её в лог со стеком и отвечает 500,
+// чтобы ошибка одного запроса не останавливала процесс
+func recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			zerolog.Ctx(r.Context()).Error().
+				Str("panic", fmt.Sprint(rec)).
+				Bytes("stack", debug.Stack()).
+				Msg("Паника при обработке запроса")
+			err := apperr.Internal("внутренняя ошибка сервера", fmt.Errorf("panic: %v", rec))
+			if isAPIRequest(r) {
+				writeErrorJSON(w, err)
+				return
+			}
+			renderErrorPage(w, r, err)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,122 @@
+package ts_service_api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/DmitriySama/teammate_search/internal/apperr"
+)
+
+type ErrorsSuite struct {
+	suite.Suite
+}
+
+func TestErrorsSuite(t *testing.T) {
+	suite.Run(t, new(ErrorsSuite))
+}
+
+func (s *ErrorsSuite) SetupTest() {
+	s.T().Setenv("FRONTEND_PATH", "../../frontend")
+}
+
+func panicking(http.ResponseWriter, *http.Request) {
+	panic("boom")
+}
+
+func (s *ErrorsSuite) serve(handler http.Handler, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func (s *ErrorsSuite) TestRecoverer_HTMLRoute() {
+	rec := s.serve(recoverer(http.HandlerFunc(panicking)), "/main/home")
+
+	s.Equal(http.StatusInternalServerError, rec.Code)
+	s.Contains(rec.Header().Get("Content-Type"), "text/html")
+	s.Contains(rec.Body.String(), "внутренняя ошибка сервера")
+	s.NotContains(rec.Body.String(), "boom")
+}
+
+func (s *ErrorsSuite) TestRecoverer_APIRoute() {
+	rec := s.serve(recoverer(http.HandlerFunc(panicking)), "/api/v1/search")
+
+	s.Equal(http.StatusInternalServerError, rec.Code)
+	s.Contains(rec.Header().Get("Content-Type"), "application/json")
+	var body errorBody
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &body))
+	s.Equal(errCodeInternal, body.Error.Code)
+	s.Equal("внутренняя ошибка сервера", body.Error.Message)
+}
+
+func (s *ErrorsSuite) TestRecoverer_PassesThrough() {
+	rec := s.serve(recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})), "/api/v1/search")
+
+	s.Equal(http.StatusNoContent, rec.Code)
+}
+
+func (s *ErrorsSuite) TestRecoverer_AbortHandlerRepanics() {
+	handler := recoverer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	s.PanicsWithValue(http.ErrAbortHandler, func() { s.serve(handler, "/main/home") })
+}
+
+// kindCases ошибки каждого вида с ожидаемым статусом и кодом JSON-ошибки
+var kindCases = []struct {
+	name   string
+	err    error
+	status int
+	code   string
+}{
+	{"validation", apperr.Validation("неверный запрос"), http.StatusBadRequest, errCodeBadRequest},
+	{"invalid fields", apperr.Invalid(apperr.FieldErrors{"age": "неверный возраст"}), http.StatusBadRequest, errCodeBadRequest},
+	{"unauthorized", apperr.Unauthorized("нужен вход"), http.StatusUnauthorized, errCodeUnauthorized},
+	{"not found", apperr.NotFound("не найдено"), http.StatusNotFound, errCodeNotFound},
+	{"conflict", apperr.Conflict("уже существует"), http.StatusConflict, errCodeConflict},
+	{"internal", apperr.Internal("внутренняя ошибка", errors.New("db down")), http.StatusInternalServerError, errCodeInternal},
+	{"plain error", errors.New("db down"), http.StatusInternalServerError, errCodeInternal},
+}
+
+func (s *ErrorsSuite) TestWriteServiceError_KindToStatus() {
+	for _, tc := range kindCases {
+		s.Run(tc.name, func() {
+			rec := httptest.NewRecorder()
+			writeServiceError(rec, httptest.NewRequest(http.MethodGet, "/api/v1/search", nil), tc.err)
+
+			s.Equal(tc.status, rec.Code)
+			var body errorBody
+			s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &body))
+			s.Equal(tc.code, body.Error.Code)
+			s.NotContains(body.Error.Message, "db down")
+		})
+	}
+}
+
+func (s *ErrorsSuite) TestRenderError_KindToStatus() {
+	for _, tc := range kindCases {
+		s.Run(tc.name, func() {
+			rec := httptest.NewRecorder()
+			renderError(rec, httptest.NewRequest(http.MethodGet, "/main/home", nil), tc.err)
+
+			s.Equal(tc.status, rec.Code)
+			s.Contains(rec.Header().Get("Content-Type"), "text/html")
+			s.NotContains(rec.Body.String(), "db down")
+		})
+	}
+}
+
+func (s *ErrorsSuite) TestRenderError_UnauthorizedLinksToLogin() {
+	rec := httptest.NewRecorder()
+	renderError(rec, httptest.NewRequest(http.MethodGet, "/main/home", nil), apperr.Unauthorized("нужен вход"))
+
+	s.Contains(rec.Body.String(), `href="/login"`)
+}
@@ -0,0 +1,244 @@
+package ts_service_api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+type credentialsRequest struct {
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	Description string `json:"description"`
+	Age         int    `json:"age"`
+}
+
+// authResponse возвращается при входе и регистрации, Session можно передавать
+// в заголовке Authorization: Bearer вместо cookie
+type authResponse struct {
+	User    *models.User `json:"user"`
+	Session string       `json:"session"`
+}
+
+func (a *API) v1Routes(r chi.Router) {
+	r.Post("/register", a.apiRegister)
+	r.Post("/login", a.apiLogin)
+	r.Post("/logout", a.apiLogout)
+
+	r.Get("/languages", a.apiLanguages)
+	r.Get("/games", a.apiGames)
+	r.Get("/genres", a.apiGenres)
+	r.Get("/apps", a.apiApps)
+
+	r.Get("/analytics/trending", a.apiTrending)
+
+	r.Group(func(r chi.Router) {
+		r.Use(requireUserJSON)
+		r.Get("/profile", a.apiGetProfile)
+		r.Put("/profile", a.apiUpdateProfile)
+		r.Get("/search", a.apiSearch)
+		r.Get("/recommendations", a.apiRecommendations)
+		r.Get("/users/suggest", a.apiSuggestUsernames)
+		r.Get("/teammates", a.apiTeammates)
+		r.Post("/teammates/{username}/{action}", a.apiTeammateAction)
+	})
+}
+
+// requireUserJSON отвечает 401, если запрос пришёл без действующей сессии
+func requireUserJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if currentUser(r) == nil {
+			writeError(w, http.StatusUnauthorized, errCodeUnauthorized, "требуется авторизация")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *API) apiRegister(w http.ResponseWriter, r *http.Request) {
+	var req credentialsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errCodeBadRequest, "некорректное тело запроса")
+		return
+	}
+	user, err := a.service.Register(r.Context(), req.Username, req.Password, req.Description, req.Age)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	a.startAPISession(w, r, user, http.StatusCreated)
+}
+
+func (a *API) apiLogin(w http.ResponseWriter, r *http.Request) {
+	var req credentialsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errCodeBadRequest, "некорректное тело запроса")
+		return
+	}
+
+	user, err := a.service.Login(r.Context(), req.Username, req.Password)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	a.startAPISession(w, r, user, http.StatusOK)
+}
+
+func (a *API) startAPISession(w http.ResponseWriter, r *http.Request, user *models.User, status int) {
+	session, err := a.sessions.Start(r.Context(), w, user.ID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, status, authResponse{User: user, Session: session})
+}
+
+func (a *API) apiLogout(w http.ResponseWriter, r *http.Request) {
+	if err := a.sessions.Destroy(w, r); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) apiGetProfile(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, currentUser(r))
+}
+
+func (a *API) apiUpdateProfile(w http.ResponseWriter, r *http.Request) {
+	var upd models.UserUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		writeError(w, http.StatusBadRequest, errCodeBadRequest, "некорректное тело запроса")
+		return
+	}
+
+	user, err := a.service.UpdateProfile(r.Context(), currentUser(r).ID, upd)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (a *API) apiSearch(w http.ResponseWriter, r *http.Request) {
+	req := parseSearchForm(r)
+	req.ViewerID = currentUser(r).ID
+
+	if req.Cursor == "" {
+		if err := a.pg.FilterData(r.Context(), req.Filter); err != nil {
+			zerolog.Ctx(r.Context()).Error().Err(err).Msg("Ошибка сохранения данных фильтра")
+		}
+	}
+
+	page, err := a.service.SearchUsers(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// apiRecommendations отдаёт самых совместимых с текущим пользователем тиммейтов,
+// limit — размер выдачи, по умолчанию из настроек, не больше числа оцениваемых кандидатов
+func (a *API) apiRecommendations(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > 100 {
+			writeError(w, http.StatusBadRequest, errCodeBadRequest, "limit должен быть от 1 до 100")
+			return
+		}
+		limit = parsed
+	}
+
+	recommendations, err := a.service.Recommend(r.Context(), currentUser(r), limit)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, recommendations)
+}
+
+func (a *API) apiLanguages(w http.ResponseWriter, r *http.Request) {
+	languages, err := a.service.GetLanguages(r.Context())
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, languages)
+}
+
+func (a *API) apiGames(w http.ResponseWriter, r *http.Request) {
+	games, err := a.service.GetGames(r.Context())
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, games)
+}
+
+func (a *API) apiGenres(w http.ResponseWriter, r *http.Request) {
+	genres, err := a.service.GetGenres(r.Context())
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, genres)
+}
+
+func (a *API) apiApps(w http.ResponseWriter, r *http.Request) {
+	apps, err := a.service.GetApps(r.Context())
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, apps)
+}
+
+// apiTrending отдаёт топ значений фильтров поиска:
+// granularity=hour|day, window — длительность в формате Go (24h), limit — размер топа по каждому измерению
+func (a *API) apiTrending(w http.ResponseWriter, r *http.Request) {
+	granularity := r.URL.Query().Get("granularity")
+	window := 24 * time.Hour
+	switch granularity {
+	case "", models.TrendHour:
+		granularity = models.TrendHour
+	case models.TrendDay:
+		window = 7 * 24 * time.Hour
+	default:
+		writeError(w, http.StatusBadRequest, errCodeBadRequest, "granularity должен быть hour или day")
+		return
+	}
+
+	if value := r.URL.Query().Get("window"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, errCodeBadRequest, "некорректное окно window")
+			return
+		}
+		window = parsed
+	}
+
+	limit := 10
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > 100 {
+			writeError(w, http.StatusBadRequest, errCodeBadRequest, "limit должен быть от 1 до 100")
+			return
+		}
+		limit = parsed
+	}
+
+	trending, err := a.service.GetTrending(r.Context(), granularity, window, limit)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, trending)
+}
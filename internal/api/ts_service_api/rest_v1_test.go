@@ -0,0 +1,65 @@
+package ts_service_api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/DmitriySama/teammate_search/internal/models"
+	tsService "github.com/DmitriySama/teammate_search/internal/services/teammateSearchService"
+	"github.com/DmitriySama/teammate_search/internal/services/teammateSearchService/mocks"
+)
+
+type RestV1Suite struct {
+	suite.Suite
+	storage *mocks.MockUsersStorage
+	api     *API
+	user    *models.User
+}
+
+func TestRestV1Suite(t *testing.T) {
+	suite.Run(t, new(RestV1Suite))
+}
+
+func (s *RestV1Suite) SetupTest() {
+	s.storage = mocks.NewMockUsersStorage(s.T())
+	cache := mocks.NewMockUsersCache(s.T())
+	s.api = &API{service: tsService.New(s.storage, cache, tsService.RecommendOptions{})}
+	s.user = &models.User{ID: 3, Username: "player"}
+}
+
+// request запрос от имени s.user, как после loadUser
+func (s *RestV1Suite) request(method, target, body string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	return r.WithContext(context.WithValue(r.Context(), userCtxKey, s.user))
+}
+
+func (s *RestV1Suite) TestUpdateProfile_GoesThroughService() {
+	hideAge := true
+	// Описание обрезано сервисом, не заданные настройки приватности остаются nil
+	upd := models.UserUpdate{Age: 30, Description: "evenings", PrivacyUpdate: models.PrivacyUpdate{HideAge: &hideAge}}
+	s.storage.On("UpdateUser", mock.Anything, 3, upd).
+		Return(&models.User{ID: 3, Username: "player", Age: 30}, nil)
+
+	rec := httptest.NewRecorder()
+	s.api.apiUpdateProfile(rec, s.request(http.MethodPut, "/api/v1/profile", `{"age": 30, "description": " evenings ", "hide_age": true}`))
+
+	s.Equal(http.StatusOK, rec.Code)
+	var user models.User
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &user))
+	s.Equal(30, user.Age)
+}
+
+func (s *RestV1Suite) TestUpdateProfile_InvalidBody() {
+	rec := httptest.NewRecorder()
+	s.api.apiUpdateProfile(rec, s.request(http.MethodPut, "/api/v1/profile", `{"age": "old"}`))
+
+	s.Equal(http.StatusBadRequest, rec.Code)
+	s.storage.AssertNotCalled(s.T(), "UpdateUser")
+}
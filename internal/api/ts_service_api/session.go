@@ -0,0 +1,48 @@
+package ts_service_api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog"
+
+	"github.com/DmitriySama/teammate_search/internal/models"
+	"github.com/DmitriySama/teammate_search/internal/session"
+)
+
+type ctxKey int
+
+const userCtxKey ctxKey = iota
+
+// loadUser подгружает пользователя текущей сессии в контекст запроса
+func (a *API) loadUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := a.sessions.UserID(r)
+		if err != nil {
+			if !errors.Is(err, session.ErrNotFound) {
+				zerolog.Ctx(r.Context()).Error().Err(err).Msg("Ошибка чтения сессии")
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, err := a.pg.GetUserByID(r.Context(), userID)
+		if err != nil {
+			zerolog.Ctx(r.Context()).Error().Err(err).Int("user_id", userID).Msg("Ошибка загрузки пользователя сессии")
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userCtxKey, user)
+		logger := zerolog.Ctx(ctx).With().Int("user_id", user.ID).Logger()
+		ctx = logger.WithContext(ctx)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// currentUser возвращает пользователя текущего запроса или nil
+func currentUser(r *http.Request) *models.User {
+	user, _ := r.Context().Value(userCtxKey).(*models.User)
+	return user
+}
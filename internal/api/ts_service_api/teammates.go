@@ -0,0 +1,101 @@
+package ts_service_api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+// teammateStatusAfter состояние связи после успешного действия с заявкой
+var teammateStatusAfter = map[string]string{
+	models.TeammateActionRequest: models.TeammateStatusOutgoing,
+	models.TeammateActionAccept:  models.TeammateStatusTeammates,
+	models.TeammateActionDecline: models.TeammateStatusNone,
+	models.TeammateActionCancel:  models.TeammateStatusNone,
+	models.TeammateActionRemove:  models.TeammateStatusNone,
+}
+
+// teammateStatusResponse ответ на действие с заявкой
+type teammateStatusResponse struct {
+	Username string `json:"username"`
+	Status   string `json:"status"`
+}
+
+// HandleTeammates страница входящих и исходящих заявок и тиммейтов
+func (a *API) HandleTeammates(w http.ResponseWriter, r *http.Request) {
+	if a.EmptyUserCheck(w, r) {
+		return
+	}
+	user := currentUser(r)
+	lists, err := a.teammates(r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render(w, r, "teammates.html", map[string]interface{}{
+		"Username":  user.Username,
+		"Incoming":  lists.Incoming,
+		"Outgoing":  lists.Outgoing,
+		"Teammates": lists.Teammates,
+	})
+}
+
+// HandleTeammateAction выполняет действие с заявкой из формы и возвращает на страницу next,
+// по умолчанию — на страницу тиммейтов
+func (a *API) HandleTeammateAction(w http.ResponseWriter, r *http.Request) {
+	if a.EmptyUserCheck(w, r) {
+		return
+	}
+	err := a.service.TeammateAction(r.Context(), currentUser(r), chi.URLParam(r, "username"), chi.URLParam(r, "action"))
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, localRedirect(r.FormValue("next"), "/teammates"), http.StatusSeeOther)
+}
+
+// localRedirect возвращает next, если это путь на этом же сайте, иначе fallback
+func localRedirect(next, fallback string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return fallback
+	}
+	return next
+}
+
+// apiTeammates отдаёт заявки и тиммейтов текущего пользователя
+func (a *API) apiTeammates(w http.ResponseWriter, r *http.Request) {
+	lists, err := a.teammates(r)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, lists)
+}
+
+// apiTeammateAction выполняет действие с заявкой и отвечает новым состоянием связи
+func (a *API) apiTeammateAction(w http.ResponseWriter, r *http.Request) {
+	username, action := chi.URLParam(r, "username"), chi.URLParam(r, "action")
+	if err := a.service.TeammateAction(r.Context(), currentUser(r), username, action); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, teammateStatusResponse{Username: username, Status: teammateStatusAfter[action]})
+}
+
+// teammates списки заявок текущего пользователя со ссылками на профили и аватары
+func (a *API) teammates(r *http.Request) (*models.TeammateLists, error) {
+	lists, err := a.service.Teammates(r.Context(), currentUser(r).ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range [][]models.Teammate{lists.Incoming, lists.Outgoing, lists.Teammates} {
+		for i := range list {
+			list[i].Avatar = avatarURL(list[i].Username)
+			list[i].Profile = profileURL(list[i].Username)
+		}
+	}
+	return lists, nil
+}
@@ -7,18 +7,24 @@ import (
 	"sync"
 	"os"
 
-	"html/template"
-	"log"
+	"slices"
 	"strconv"
 	"strings"
 	"path/filepath"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
 
 	"github.com/DmitriySama/teammate_search/api/swagger"
 	tsService "github.com/DmitriySama/teammate_search/internal/services/teammateSearchService"
 	
+	"github.com/DmitriySama/teammate_search/internal/apperr"
+	"github.com/DmitriySama/teammate_search/internal/health"
+	"github.com/DmitriySama/teammate_search/internal/logging"
+	"github.com/DmitriySama/teammate_search/internal/metrics"
 	"github.com/DmitriySama/teammate_search/internal/models"
+	"github.com/DmitriySama/teammate_search/internal/session"
 	"github.com/DmitriySama/teammate_search/internal/storage/pgstorage"
 )
 
@@ -28,18 +34,27 @@ type API struct {
 	once        sync.Once
 	swaggerSpec []byte
     pg *pgstorage.PGstorage
-    user *models.User
+    sessions *session.Manager
+    health *health.Checker
+    logger zerolog.Logger
 }
 
-func New(service *tsService.Service, serviceName string, pg *pgstorage.PGstorage) *API {
-	return &API{service: service, serviceName: serviceName, pg: pg}
+func New(service *tsService.Service, serviceName string, pg *pgstorage.PGstorage, sessions *session.Manager, health *health.Checker, logger zerolog.Logger) *API {
+	return &API{service: service, serviceName: serviceName, pg: pg, sessions: sessions, health: health, logger: logger}
 }
 
 func (a *API) Router() http.Handler {
 	router := chi.NewRouter()
 	http.DefaultServeMux.HandleFunc("/", a.MIMEProcessing)
-
-	router.Get("/health", a.health)
+	router.Use(logging.Middleware(a.logger))
+	router.Use(metrics.HTTPMiddleware)
+	router.Use(recoverer)
+	router.Use(a.loadUser)
+
+	router.Get("/health", a.healthHandler)
+	router.Get("/livez", a.health.Livez)
+	router.Get("/readyz", a.health.Readyz)
+	router.Handle("/metrics", metrics.Handler())
 	router.Get("/swagger", a.swaggerUI)
 	router.Get("/swagger/web.swagger.json", a.swaggerSpecHandler)
 	
@@ -48,6 +63,7 @@ func (a *API) Router() http.Handler {
 
 	router.Get("/login", a.LoginPage)
 	router.Post("/login", a.LoginHandler)
+	router.Post("/logout", a.LogoutHandler)
 
 	router.Get("/main/home", a.MainMainHandler)
 
@@ -58,6 +74,14 @@ func (a *API) Router() http.Handler {
 	router.Get("/main/search", a.MainSearchHandler)
 	router.Post("/main/search", a.MainSearchHandler)
 	router.Post("/main/select-user", a.SelectUser)
+
+	router.Get("/users/{username}", a.HandleUserProfile)
+	router.Get("/avatars/{file}", a.avatarHandler)
+
+	router.Get("/teammates", a.HandleTeammates)
+	router.Post("/teammates/{username}/{action}", a.HandleTeammateAction)
+
+	router.Route("/api/v1", a.v1Routes)
 	return router
 }
 
@@ -66,41 +90,63 @@ type SelectUser struct {
 }
 
 func (a *API) SelectUser(w http.ResponseWriter, r *http.Request) {    
-    a.EmptyUserCheck(w, r)
+    if a.EmptyUserCheck(w, r) {
+        return
+    }
     var req SelectUser
     if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-        w.Header().Set("Content-Type", "application/json")
-        w.WriteHeader(http.StatusBadRequest)
+        writeError(w, http.StatusBadRequest, errCodeBadRequest, "некорректное тело запроса")
+        return
+    }
+    if err := a.pg.SelectUser(r.Context(), req.Username); err != nil {
+        zerolog.Ctx(r.Context()).Error().Err(err).Msg("Ошибка сохранения события просмотра")
     }
-    a.pg.SelectUser(req.Username)
 }
 
 func (a *API) RegisterPage(w http.ResponseWriter, r *http.Request) {
-	template.Must(template.ParseFiles(getFrontendPath()+"/register.html")).Execute(w, nil)
+	render(w, r, "register.html", formPage{})
 }
 
 func (a *API) RegisterHandler(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		log.Println("Ошибка при разборе формы")
-	} else {
-		age, _ := strconv.Atoi(r.FormValue("age"))
-
-		result, err := a.pg.Register(r.FormValue("username"), r.FormValue("password"), r.FormValue("description"), age)
-		if err != nil {
-			log.Fatal("Ошибка регистрации:", err)
-		}
-		if result.Success {
-			log.Printf("Пользователь зарегистрирован: ID=%d", result.User.ID)
-			a	.user = result.User
-			http.Redirect(w, r, "/main/home", http.StatusSeeOther)
-		} else {
-			log.Printf("Ошибка регистрации: %s", result.Message)
-		}
+		renderError(w, r, apperr.Validation("некорректная форма регистрации"))
+		return
+	}
+	// Пароль в форму не возвращается
+	values := map[string]string{
+		"username":    strings.TrimSpace(r.FormValue("username")),
+		"description": strings.TrimSpace(r.FormValue("description")),
+		"age":         strings.TrimSpace(r.FormValue("age")),
+	}
+	password := r.FormValue("password")
+
+	age, err := strconv.Atoi(values["age"])
+	if err != nil {
+		fields := tsService.ValidateRegistration(values["username"], password, values["description"], 0)
+		fields["age"] = "возраст должен быть числом"
+		renderForm(w, r, "register.html", values, apperr.Invalid(fields))
+		return
 	}
+
+	user, err := a.service.Register(r.Context(), values["username"], password, values["description"], age)
+	if err != nil {
+		renderForm(w, r, "register.html", values, err)
+		return
+	}
+	a.startPageSession(w, r, user.ID)
+}
+
+// startPageSession создаёт сессию после входа или регистрации через форму и открывает главную
+func (a *API) startPageSession(w http.ResponseWriter, r *http.Request, userID int) {
+	if _, err := a.sessions.Start(r.Context(), w, userID); err != nil {
+		renderError(w, r, apperr.Internal("не удалось создать сессию", err))
+		return
+	}
+	http.Redirect(w, r, "/main/home", http.StatusSeeOther)
 }
 
 func (a *API) LoginPage(w http.ResponseWriter, r *http.Request) {
-	template.Must(template.ParseFiles(getFrontendPath()+"/login.html")).Execute(w, nil)
+	render(w, r, "login.html", formPage{})
 }
 
 func getFrontendPath() string {
@@ -119,22 +165,25 @@ func getFrontendPath() string {
 }
 
 func (a *API) LoginHandler(w http.ResponseWriter, r *http.Request) {
-    if err := r.ParseForm(); err != nil {
-		log.Println("Ошибка при разборе формы")
-	} else {
-		
-		result, err := a.pg.Login(r.FormValue("username"), r.FormValue("password"))
-		if err != nil {
-			log.Fatal("Ошибка входа:", err)
-		}
-		if result.Success {
-			log.Printf("Пользователь авторизован: ID=%d", result.User.ID)
-			a.user = result.User
-			http.Redirect(w, r, "/main/home", http.StatusSeeOther)
-		} else {
-			log.Printf("Ошибка авторизации: %s", result.Message)
-		}
+	if err := r.ParseForm(); err != nil {
+		renderError(w, r, apperr.Validation("некорректная форма входа"))
+		return
 	}
+
+	values := map[string]string{"username": strings.TrimSpace(r.FormValue("username"))}
+	user, err := a.service.Login(r.Context(), values["username"], r.FormValue("password"))
+	if err != nil {
+		renderForm(w, r, "login.html", values, err)
+		return
+	}
+	a.startPageSession(w, r, user.ID)
+}
+
+func (a *API) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	if err := a.sessions.Destroy(w, r); err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Ошибка удаления сессии")
+	}
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
 }
 
 
@@ -143,75 +192,110 @@ func (a *API) MainMainHandler(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-	profileData := a.GetDataToShow(r, "main")
-	template.Must(template.ParseFiles(getFrontendPath()+"/main.html")).Execute(w, profileData)
+	profileData, err := a.GetDataToShow(r, "main")
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render(w, r, "main.html", profileData)
+}
+
+type sortOption struct {
+    Value string
+    Title string
+}
+
+var searchSortOptions = []sortOption{
+    {Value: models.SortNewest, Title: "Сначала новые"},
+    {Value: models.SortAge, Title: "По возрасту"},
+    {Value: models.SortPopularity, Title: "По популярности"},
+    {Value: models.SortBestMatch, Title: "Лучшее совпадение"},
+    {Value: models.SortRelevance, Title: "По релевантности описания"},
+}
+
+// parseSearchForm собирает параметры поиска из формы или query-строки
+func parseSearchForm(r *http.Request) models.SearchRequest {
+    age0, _ := strconv.Atoi(r.FormValue("age0"))
+    age1, _ := strconv.Atoi(r.FormValue("age1"))
+    limit, _ := strconv.Atoi(r.FormValue("limit"))
+    return models.SearchRequest{
+        Filter: models.FilterData{
+            Age0: age0,
+            Age1: age1,
+            Game: formValues(r, "game"),
+            Genre: formValues(r, "genre"),
+            Language: formValues(r, "language"),
+            App: formValues(r, "app"),
+            Match: r.FormValue("match"),
+            Query: r.FormValue("q"),
+        },
+        Sort: r.FormValue("sort"),
+        Cursor: r.FormValue("cursor"),
+        Limit: limit,
+    }
+}
+
+// formValues возвращает все значения поля формы или query-строки, например мультивыбора
+func formValues(r *http.Request, name string) models.FilterValues {
+    r.FormValue(name) // разбирает форму, если она ещё не разобрана
+    return models.FilterValues(r.Form[name])
 }
 
 func (a *API) MainSearchHandler(w http.ResponseWriter, r *http.Request) {    
     if a.EmptyUserCheck(w, r) {
         return
     }
-    profileData := a.GetDataToShow(r, "search")
+    profileData, err := a.GetDataToShow(r, "search")
+    if err != nil {
+        renderError(w, r, err)
+        return
+    }
+    profileData["Sorts"] = searchSortOptions
 	
 	if r.Method == "GET" {
-		profileData["User"] = []models.User{}
-		template.Must(template.ParseFiles(getFrontendPath()+"/main_search.html")).Execute(w, profileData)
+		profileData["User"] = []models.UserListShow{}
+		profileData["Filter"] = models.FilterData{}
+		profileData["Sort"] = models.SortNewest
+		profileData["Limit"] = models.DefaultSearchLimit
+		render(w, r, "main_search.html", profileData)
 	}
 
     if r.Method == "POST" {
+        logger := zerolog.Ctx(r.Context())
         if err := r.ParseForm(); err != nil {
-            log.Println("Ошибка при разборе формы")
+            renderError(w, r, apperr.Validation("некорректная форма поиска"))
         } else {
-            // Отправление данных фильтров через KAFKA
-            age, _ := strconv.Atoi(r.FormValue("age"))
-            age1, _ := strconv.Atoi(r.FormValue("age1"))
-            fd := models.FilterData{
-                Age0: age,
-                Age1: age1,
-                Game: r.FormValue("game"),
-                Genre: r.FormValue("genre"),
-                Language: r.FormValue("language"),
-                App: r.FormValue("app"),
-            }
-            err := a.pg.FilterData(fd)
-            if err != nil {
-                log.Println("===========")
-            }
-            // Получение пользователей
-            users, _ := a.pg.GetUsers(r)
-            users_v2 := []models.UserListShow{}
-            
-            for users.Next() {
-                var username, description, game, genre, language, app string
-                var age int
-
-                if err := users.Scan(&username, &age, &description, &game, &language, &app, &genre); err != nil {
-                    log.Fatal(err)
+            req := parseSearchForm(r)
+            req.ViewerID = currentUser(r).ID
+
+            // Данные фильтров пишутся в outbox (релей отправит их в KAFKA) только для нового поиска, не для перелистывания
+            if req.Cursor == "" {
+                err := a.pg.FilterData(r.Context(), req.Filter)
+                if err != nil {
+                    logger.Error().Err(err).Msg("Ошибка сохранения данных фильтра")
                 }
-                users_v2 = append(users_v2, models.UserListShow{
-                    Username: username,
-                    Age: age,
-                    Description: description,
-                    MostLikeGame: game,
-                    MostLikeGenre: genre,
-                    Language: language,
-                })
             }
-
-            data := map[string]interface{}{
-                "MyUsername": a.user.Username,
-                "User": users_v2,
+            // Получение пользователей
+            page, err := a.service.SearchUsers(r.Context(), req)
+            if err != nil {
+                renderError(w, r, err)
+                return
             }
+            profileData["User"] = page.Users
+            profileData["NextCursor"] = page.NextCursor
+            profileData["PrevCursor"] = page.PrevCursor
+            profileData["Filter"] = req.Filter
+            profileData["Sort"] = req.Sort
+            profileData["Limit"] = req.Limit
 
             // Отрисовка пользователей
-			template.Must(template.ParseFiles(getFrontendPath()+"/main_search.html")).Execute(w, data)
+			render(w, r, "main_search.html", profileData)
         }
     }
 }
 
 func (a *API) EmptyUserCheck(w http.ResponseWriter, r *http.Request) bool {
-    log.Printf("api: %+v\n", a)
-    if a.user == nil {
+    if currentUser(r) == nil {
         http.Redirect(w, r, "/login", http.StatusSeeOther)
         return true
     }
@@ -222,69 +306,169 @@ func (a *API) HandleGetProfile(w http.ResponseWriter, r *http.Request) {
     if a.EmptyUserCheck(w, r) {
         return
     }
-	profileData := a.GetDataToShow(r, "GetProfile")
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	template.Must(template.ParseFiles(getFrontendPath()+"/profile_look.html")).Execute(w, profileData)
+	profileData, err := a.GetDataToShow(r, "GetProfile")
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render(w, r, "profile_look.html", profileData)
 }
 
-func (a *API) GetDataToShow(r *http.Request, choise string) (map[string]interface{}){
+// profileLookData данные шаблона profile_look.html, own — страница своего профиля.
+// Другим игрокам скрытые настройками приватности поля не передаются в шаблон вовсе
+func profileLookData(user *models.User, own bool) map[string]interface{} {
+    if !own && user.HideProfile {
+        return map[string]interface{}{
+            "Own": false,
+            "Hidden": true,
+            "Username": user.Username,
+        }
+    }
+    data := map[string]interface{}{
+        "Own": own,
+        "Hidden": false,
+        "Username": user.Username,
+        "Description": user.Description,
+        "SpeakingApp": user.App,
+        "MLGame": strings.Join(user.Games, ", "),
+        "MLGenre": strings.Join(user.Genres, ", "),
+        "Language": strings.Join(user.Languages, ", "),
+        "App": strings.Join(user.Apps, ", "),
+        "MemberSince": user.CreatedAt.Format("02.01.2006"),
+    }
+    showAge, showPopularity := own || !user.HideAge, own || !user.HidePopularity
+    data["ShowAge"], data["ShowPopularity"] = showAge, showPopularity
+    if showAge {
+        data["Age"] = user.Age
+    }
+    if showPopularity {
+        data["Popularity"] = user.Popularity
+    }
+    return data
+}
+
+// GetDataToShow собирает данные шаблона страницы choise. Ошибка загрузки справочников
+// возвращается, а недоступные тренды и рекомендации на главной только логируются
+func (a *API) GetDataToShow(r *http.Request, choise string) (map[string]interface{}, error){
+    user := currentUser(r)
     var data map[string]interface{}
     switch choise {
         case "main": {
-            userCount, _ := a.pg.GetUserCount()
+            userCount, err := a.pg.GetUserCount()
+            if err != nil {
+                return nil, apperr.Internal("не удалось загрузить главную страницу", err)
+            }
+            trending, err := a.service.GetTrending(r.Context(), models.TrendHour, 24*time.Hour, 3)
+            if err != nil {
+                zerolog.Ctx(r.Context()).Error().Err(err).Msg("Ошибка получения трендов поиска")
+                trending = &models.Trending{}
+            }
+            recommendations, err := a.service.Recommend(r.Context(), user, 0)
+            if err != nil {
+                zerolog.Ctx(r.Context()).Error().Err(err).Msg("Ошибка подбора рекомендаций")
+            }
             data = map[string]interface{}{
-                "Username": a.user.Username,
+                "Username": user.Username,
                 "UserCount": userCount,
+                "TrendingGames": trending.Items[models.TrendGame],
+                "TrendingGenres": trending.Items[models.TrendGenre],
+                "Recommendations": recommendations,
             }
         }   
         case "search": {
-            languages, _ := a.service.GetLanguages(r.Context())
-            languages = append([]models.Language{{ID: -1, Lang: "Любой"}}, languages...)
-            games, _ := a.service.GetGames(r.Context())
-            games = append([]models.Games{{ID: -1, Game: "Любая"}}, games...)
-            genres, _ := a.service.GetGenres(r.Context())
-            genres = append([]models.Genres{{ID: -1, Genre: "Любой"}}, genres...)
-            apps, _ := a.service.GetApps(r.Context())
-            apps = append([]models.Apps{{ID: -1, App: "Любой"}}, apps...)
-
+            dicts, err := a.loadDictionaries(r)
+            if err != nil {
+                return nil, err
+            }
             data = map[string]interface{}{
-                "MyUsername": a.user.Username,
-                "Languages": languages,
-                "Games": games,
-                "Genres": genres,
-                "Apps": apps,
+                "MyUsername": user.Username,
+                // Ничего не выбрано в мультивыборе — подходит любое значение
+                "Languages": dicts.languages,
+                "Games": dicts.games,
+                "Genres": dicts.genres,
+                "Apps": dicts.apps,
             }
         }
         case "GetProfile": {
-            data = map[string]interface{}{
-                "Username": a.user.Username,
-                "Age": a.user.Age,
-                "Description": a.user.Description,
-                "SpeakingApp": a.user.App,
-                "MLGame": a.user.MostLikeGame,
-                "MLGenre": a.user.MostLikeGenre,
-                "Language": a.user.Language,
-                "App": a.user.App,
-            }
+            data = profileLookData(user, true)
         } 
         case "UpdateProfile": {
-            languages, _ := a.pg.GetLanguages(r.Context())
-            games, _ := a.pg.GetGames(r.Context())
-            genres, _ := a.pg.GetGenres(r.Context())
-            apps, _ := a.pg.GetApps(r.Context())
+            dicts, err := a.loadDictionaries(r)
+            if err != nil {
+                return nil, err
+            }
             data = map[string]interface{}{
-                "Username": a.user.Username,
-                "Age": a.user.Age,
-                "Description": a.user.Description,
-                "SpeakingApp": a.user.App,
-                "Languages": languages,
-                "Games": games,
-                "Apps": apps,
-                "Genres": genres,
+                "Username": user.Username,
+                "Age": user.Age,
+                "Description": user.Description,
+                "SpeakingApp": user.App,
+                "Privacy": user.Privacy,
+                "Errors": apperr.FieldErrors(nil),
             }
+            dicts.addOptions(data, user.Preferences)
         }
     }
-    return data
+    return data, nil
+}
+
+// dictionaries справочники для выпадающих списков форм
+type dictionaries struct {
+    languages []models.Language
+    games     []models.Games
+    genres    []models.Genres
+    apps      []models.Apps
+}
+
+// preferenceOption значение справочника в мультивыборе профиля
+type preferenceOption struct {
+    ID       int
+    Name     string
+    Selected bool
+}
+
+// preferenceOptions ставит выбранные значения первыми в порядке приоритета, остальные —
+// в порядке справочника, поэтому отправленная форма сохраняет приоритеты
+func preferenceOptions[T any](items []T, selected []int, entry func(T) (int, string)) []preferenceOption {
+    options := make([]preferenceOption, 0, len(items))
+    for _, id := range selected {
+        for _, item := range items {
+            if itemID, name := entry(item); itemID == id {
+                options = append(options, preferenceOption{ID: id, Name: name, Selected: true})
+            }
+        }
+    }
+    for _, item := range items {
+        if id, name := entry(item); !slices.Contains(selected, id) {
+            options = append(options, preferenceOption{ID: id, Name: name})
+        }
+    }
+    return options
+}
+
+// addOptions добавляет в данные шаблона профиля варианты мультивыборов
+func (d *dictionaries) addOptions(data map[string]interface{}, prefs models.Preferences) {
+    data["Games"] = preferenceOptions(d.games, prefs.GameIDs, func(g models.Games) (int, string) { return g.ID, g.Game })
+    data["Genres"] = preferenceOptions(d.genres, prefs.GenreIDs, func(g models.Genres) (int, string) { return g.ID, g.Genre })
+    data["Languages"] = preferenceOptions(d.languages, prefs.LanguageIDs, func(l models.Language) (int, string) { return l.ID, l.Lang })
+    data["Apps"] = preferenceOptions(d.apps, prefs.AppIDs, func(a models.Apps) (int, string) { return a.ID, a.App })
+}
+
+func (a *API) loadDictionaries(r *http.Request) (*dictionaries, error) {
+    var d dictionaries
+    var err error
+    if d.languages, err = a.service.GetLanguages(r.Context()); err != nil {
+        return nil, apperr.Internal("не удалось загрузить список языков", err)
+    }
+    if d.games, err = a.service.GetGames(r.Context()); err != nil {
+        return nil, apperr.Internal("не удалось загрузить список игр", err)
+    }
+    if d.genres, err = a.service.GetGenres(r.Context()); err != nil {
+        return nil, apperr.Internal("не удалось загрузить список жанров", err)
+    }
+    if d.apps, err = a.service.GetApps(r.Context()); err != nil {
+        return nil, apperr.Internal("не удалось загрузить список приложений", err)
+    }
+    return &d, nil
 }
 
 
@@ -293,30 +477,103 @@ func (a *API) HandleUpdateProfile(w http.ResponseWriter, r *http.Request) {
         return
     }
     if r.Method == "POST" {
-        err := a.pg.UpdateUser(r, *a.user)
+        user := currentUser(r)
+        upd, err := parseProfileForm(r)
         if err == nil {
-            log.Printf("Профиль пользователя %d обновлен", a.user.ID)
-            a.UpdateUserValues(r)
-        } else {
-            log.Fatal("Не удалось обновить")
+            _, err = a.service.UpdateProfile(r.Context(), user.ID, upd)
         }
+        if err != nil {
+            a.renderProfileForm(w, r, upd, err)
+            return
+        }
+        zerolog.Ctx(r.Context()).Info().Int("user_id", user.ID).Msg("Профиль пользователя обновлен")
         http.Redirect(w, r, "/profile/look", http.StatusSeeOther)
+        return
     }
     if r.Method == "GET" {
-        profileData := a.GetDataToShow(r, "UpdateProfile")
-        w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		template.Must(template.ParseFiles(getFrontendPath()+"/profile_update.html")).Execute(w, profileData)
+        profileData, err := a.GetDataToShow(r, "UpdateProfile")
+        if err != nil {
+            renderError(w, r, err)
+            return
+        }
+        render(w, r, "profile_update.html", profileData)
     }
 }
 
-func (a *API) UpdateUserValues(r *http.Request) {
-    a.user.Age, _ = strconv.Atoi(r.FormValue("age"))
-    a.user.Description = r.FormValue("description")
-    a.user.Language = r.FormValue("language")
-    a.user.MostLikeGame = strings.SplitN(r.FormValue("game"), " ", 2)[1]
-    a.user.MostLikeGenre = strings.SplitN(r.FormValue("genre"), " ", 2)[1]
-    a.user.Language = strings.SplitN(r.FormValue("language"), " ", 2)[1]
-    a.user.App = r.FormValue("app")
+// parseProfileForm разбирает форму профиля. Поля, которые не удалось разобрать,
+// возвращаются ошибкой валидации, остальные значения заполняются
+func parseProfileForm(r *http.Request) (models.UserUpdate, error) {
+    upd := models.UserUpdate{Description: strings.TrimSpace(r.FormValue("description"))}
+    fields := apperr.FieldErrors{}
+
+    age, err := strconv.Atoi(strings.TrimSpace(r.FormValue("age")))
+    if err != nil {
+        fields["age"] = "возраст должен быть числом"
+    }
+    upd.Age = age
+    // Снятый флажок не отправляется формой, поэтому все настройки задаются явно
+    upd.PrivacyUpdate = models.Privacy{
+        HideProfile: r.FormValue("hide_profile") != "",
+        HideAge: r.FormValue("hide_age") != "",
+        HidePopularity: r.FormValue("hide_popularity") != "",
+    }.Update()
+
+    lists := []struct {
+        name string
+        dst  *[]int
+        msg  string
+    }{
+        {"game", &upd.GameIDs, "выберите игры из списка"},
+        {"genre", &upd.GenreIDs, "выберите жанры из списка"},
+        {"language", &upd.LanguageIDs, "выберите языки из списка"},
+        {"app", &upd.AppIDs, "выберите приложения из списка"},
+    }
+    for _, f := range lists {
+        for _, value := range r.Form[f.name] {
+            value = strings.TrimSpace(value)
+            if value == "" || value == "0" {
+                continue
+            }
+            id, err := strconv.Atoi(value)
+            if err != nil || id < 0 {
+                fields[f.name] = f.msg
+                continue
+            }
+            *f.dst = append(*f.dst, id)
+        }
+    }
+
+    if len(fields) > 0 {
+        return upd, apperr.Invalid(fields)
+    }
+    return upd, nil
+}
+
+// renderProfileForm повторно показывает форму профиля с введёнными значениями и ошибками полей
+func (a *API) renderProfileForm(w http.ResponseWriter, r *http.Request, upd models.UserUpdate, err error) {
+    fields, ok := formErrors(err)
+    if !ok {
+        renderError(w, r, err)
+        return
+    }
+    data, dataErr := a.GetDataToShow(r, "UpdateProfile")
+    if dataErr != nil {
+        renderError(w, r, dataErr)
+        return
+    }
+    data["Age"] = r.FormValue("age")
+    data["Description"] = upd.Description
+    data["Privacy"] = upd.Apply(currentUser(r).Privacy)
+    data["Errors"] = fields
+    dicts, dictsErr := a.loadDictionaries(r)
+    if dictsErr != nil {
+        renderError(w, r, dictsErr)
+        return
+    }
+    dicts.addOptions(data, upd.Preferences)
+
+    status, _ := errorStatus(apperr.KindOf(err))
+    renderStatus(w, r, status, "profile_update.html", data)
 }
 
 func (a *API) MIMEProcessing(w http.ResponseWriter, r *http.Request) {
@@ -338,7 +595,7 @@ func (a *API) MIMEProcessing(w http.ResponseWriter, r *http.Request) {
     }
 }
 
-func (a *API) health(w http.ResponseWriter, _ *http.Request) {
+func (a *API) healthHandler(w http.ResponseWriter, _ *http.Request) {
 	body := map[string]string{
 		"service": a.serviceName,
 		"status":  "ok",
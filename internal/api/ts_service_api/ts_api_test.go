@@ -0,0 +1,67 @@
+package ts_service_api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+type ProfileLookSuite struct {
+	suite.Suite
+}
+
+func TestProfileLookSuite(t *testing.T) {
+	suite.Run(t, new(ProfileLookSuite))
+}
+
+func privateUser(privacy models.Privacy) *models.User {
+	return &models.User{
+		Username:    "player",
+		Age:         25,
+		Description: "ranked по вечерам",
+		Popularity:  4.5,
+		CreatedAt:   time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		Games:       []string{"Dota 2", "CS2"},
+		Privacy:     privacy,
+	}
+}
+
+func (s *ProfileLookSuite) TestPublic() {
+	data := profileLookData(privateUser(models.Privacy{}), false)
+
+	s.Equal(false, data["Hidden"])
+	s.Equal(25, data["Age"])
+	s.Equal(4.5, data["Popularity"])
+	s.Equal(true, data["ShowAge"])
+	s.Equal(true, data["ShowPopularity"])
+	s.Equal("Dota 2, CS2", data["MLGame"])
+	s.Equal("02.01.2026", data["MemberSince"])
+}
+
+func (s *ProfileLookSuite) TestHiddenProfileOnlyUsername() {
+	data := profileLookData(privateUser(models.Privacy{HideProfile: true}), false)
+
+	s.Equal(map[string]interface{}{"Own": false, "Hidden": true, "Username": "player"}, data)
+}
+
+func (s *ProfileLookSuite) TestHiddenAgeAndPopularity() {
+	data := profileLookData(privateUser(models.Privacy{HideAge: true, HidePopularity: true}), false)
+
+	s.Equal(false, data["ShowAge"])
+	s.Equal(false, data["ShowPopularity"])
+	s.NotContains(data, "Age")
+	s.NotContains(data, "Popularity")
+	s.Equal("ranked по вечерам", data["Description"])
+}
+
+func (s *ProfileLookSuite) TestOwnProfileShowsEverything() {
+	data := profileLookData(privateUser(models.Privacy{HideProfile: true, HideAge: true, HidePopularity: true}), true)
+
+	s.Equal(true, data["Own"])
+	s.Equal(false, data["Hidden"])
+	s.Equal(25, data["Age"])
+	s.Equal(4.5, data["Popularity"])
+}
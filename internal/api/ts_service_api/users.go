@@ -0,0 +1,116 @@
+package ts_service_api
+
+import (
+	"fmt"
+	"hash/fnv"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+// avatarColors фоны сгенерированных аватаров, цвет выбирается по нику
+var avatarColors = []string{"#bb86fc", "#03dac6", "#cf6679", "#ff9800", "#4caf50", "#2196f3", "#9c64e6", "#e91e63"}
+
+// avatarURL адрес аватара пользователя
+func avatarURL(username string) string {
+	return "/avatars/" + url.PathEscape(username) + ".svg"
+}
+
+// profileURL адрес публичной страницы пользователя
+func profileURL(username string) string {
+	return "/users/" + url.PathEscape(username)
+}
+
+// avatarHandler отдаёт SVG-аватар с первой буквой ника на фоне постоянного для ника цвета.
+// Загруженных аватаров нет, поэтому картинка строится для любого ника.
+// Ник может содержать точку, поэтому расширение отрезается вручную, а не шаблоном маршрута
+func (a *API) avatarHandler(w http.ResponseWriter, r *http.Request) {
+	username, ok := strings.CutSuffix(chi.URLParam(r, "file"), ".svg")
+	if !ok || username == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(username)))
+	color := avatarColors[h.Sum32()%uint32(len(avatarColors))]
+
+	letter := "?"
+	if first, _ := utf8.DecodeRuneInString(username); first != utf8.RuneError {
+		letter = string(unicode.ToUpper(first))
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="64" height="64" viewBox="0 0 64 64">`+
+		`<circle cx="32" cy="32" r="32" fill="%s"/>`+
+		`<text x="50%%" y="50%%" dy=".35em" text-anchor="middle" font-family="Segoe UI, sans-serif" font-size="30" font-weight="bold" fill="#121212">%s</text>`+
+		`</svg>`, color, html.EscapeString(letter))
+}
+
+// HandleUserProfile публичная страница профиля игрока с учётом его настроек приватности
+// и кнопками заявки в тиммейты. Просмотр чужого профиля записывается в outbox как событие популярности
+func (a *API) HandleUserProfile(w http.ResponseWriter, r *http.Request) {
+	if a.EmptyUserCheck(w, r) {
+		return
+	}
+	user, err := a.service.GetUserByUsername(r.Context(), chi.URLParam(r, "username"))
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	viewer := currentUser(r)
+	own := user.ID == viewer.ID
+	data := profileLookData(user, own)
+	if !own {
+		if err := a.pg.SelectUser(r.Context(), user.Username); err != nil {
+			zerolog.Ctx(r.Context()).Error().Err(err).Msg("Ошибка сохранения события просмотра")
+		}
+		// Без статуса страница показывается без кнопок заявки
+		status, err := a.service.TeammateStatus(r.Context(), viewer, user)
+		if err != nil {
+			zerolog.Ctx(r.Context()).Error().Err(err).Msg("Ошибка получения статуса заявки в тиммейты")
+		}
+		data["TeammateStatus"] = status
+	}
+	render(w, r, "profile_look.html", data)
+}
+
+// apiSuggestUsernames подсказки ников для автодополнения: q — введённая часть ника,
+// limit — число подсказок
+func (a *API) apiSuggestUsernames(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > models.MaxUsernameSuggest {
+			writeError(w, http.StatusBadRequest, errCodeBadRequest,
+				fmt.Sprintf("limit должен быть от 1 до %d", models.MaxUsernameSuggest))
+			return
+		}
+		limit = parsed
+	}
+
+	usernames, err := a.service.SuggestUsernames(r.Context(), r.URL.Query().Get("q"), limit)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	suggestions := make([]models.UsernameSuggestion, 0, len(usernames))
+	for _, username := range usernames {
+		suggestions = append(suggestions, models.UsernameSuggestion{
+			Username: username,
+			Avatar:   avatarURL(username),
+			Profile:  profileURL(username),
+		})
+	}
+	writeJSON(w, http.StatusOK, suggestions)
+}
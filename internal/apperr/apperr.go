@@ -0,0 +1,112 @@
+// Package apperr типизированные доменные ошибки. Вид ошибки определяет HTTP-статус,
+// а сообщение безопасно показывать пользователю. Ошибки без вида считаются внутренними
+package apperr
+
+import (
+	"errors"
+)
+
+// Kind вид доменной ошибки
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindValidation
+	KindConflict
+	KindUnauthorized
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not_found"
+	case KindValidation:
+		return "validation"
+	case KindConflict:
+		return "conflict"
+	case KindUnauthorized:
+		return "unauthorized"
+	default:
+		return "internal"
+	}
+}
+
+// FieldErrors сообщения об ошибках по полям формы: имя поля -> текст для пользователя
+type FieldErrors map[string]string
+
+// Error доменная ошибка: вид, сообщение для пользователя и необязательная причина для логов.
+// Fields заполняется у ошибок валидации, чтобы показать сообщения рядом с полями формы
+type Error struct {
+	Kind    Kind
+	Message string
+	Fields  FieldErrors
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func NotFound(message string) *Error {
+	return &Error{Kind: KindNotFound, Message: message}
+}
+
+func Validation(message string) *Error {
+	return &Error{Kind: KindValidation, Message: message}
+}
+
+// Invalid ошибка валидации с сообщениями по полям
+func Invalid(fields FieldErrors) *Error {
+	return &Error{Kind: KindValidation, Message: "проверьте правильность заполнения полей", Fields: fields}
+}
+
+func Conflict(message string) *Error {
+	return &Error{Kind: KindConflict, Message: message}
+}
+
+func Unauthorized(message string) *Error {
+	return &Error{Kind: KindUnauthorized, Message: message}
+}
+
+// Internal оборачивает непредвиденную ошибку, пользователю показывается только message
+func Internal(message string, err error) *Error {
+	return &Error{Kind: KindInternal, Message: message, Err: err}
+}
+
+// KindOf возвращает вид первой доменной ошибки в цепочке err, для остальных — KindInternal
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindInternal
+}
+
+// MessageOf возвращает текст для пользователя. Для внутренних ошибок подробности скрываются
+func MessageOf(err error) string {
+	var e *Error
+	if errors.As(err, &e) && e.Kind != KindInternal {
+		return err.Error()
+	}
+	if errors.As(err, &e) && e.Message != "" {
+		return e.Message
+	}
+	return "внутренняя ошибка сервера"
+}
+
+// FieldsOf возвращает ошибки полей из цепочки err, nil если их нет
+func FieldsOf(err error) FieldErrors {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Fields
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ApperrSuite struct {
+	suite.Suite
+}
+
+func TestApperrSuite(t *testing.T) {
+	suite.Run(t, new(ApperrSuite))
+}
+
+func (s *ApperrSuite) TestKindOf_Wrapped() {
+	err := fmt.Errorf("%w: фильтр game", Validation("некорректные параметры поиска"))
+
+	s.Equal(KindValidation, KindOf(err))
+	s.Equal("некорректные параметры поиска: фильтр game", MessageOf(err))
+}
+
+func (s *ApperrSuite) TestKindOf_PlainErrorIsInternal() {
+	err := errors.New("pq: connection refused")
+
+	s.Equal(KindInternal, KindOf(err))
+	s.Equal("внутренняя ошибка сервера", MessageOf(err))
+}
+
+func (s *ApperrSuite) TestMessageOf_InternalHidesCause() {
+	cause := errors.New("pq: connection refused")
+	err := Internal("не удалось загрузить страницу", cause)
+
+	s.ErrorIs(err, cause)
+	s.Equal("не удалось загрузить страницу", MessageOf(err))
+}
+
+func (s *ApperrSuite) TestInvalid_Fields() {
+	err := fmt.Errorf("регистрация: %w", Invalid(FieldErrors{"age": "возраст должен быть числом"}))
+
+	s.Equal(KindValidation, KindOf(err))
+	s.Equal(FieldErrors{"age": "возраст должен быть числом"}, FieldsOf(err))
+	s.Nil(FieldsOf(errors.New("pq: connection refused")))
+}
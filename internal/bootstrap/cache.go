@@ -2,30 +2,43 @@ package bootstrap
 
 import (
 	"context"
-	"log"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
 
 	"github.com/DmitriySama/teammate_search/config"
 	"github.com/DmitriySama/teammate_search/internal/cache"
 )
 
-func InitCache(cfg *config.Config) *cache.Cache {
+func InitRedis(cfg *config.Config, logger zerolog.Logger) *redis.Client {
 	redisAddr := cfg.RedisAddr()
-	log.Printf("Redis: инициализация подключения к Redis БД: %d", cfg.Redis.DB)
+	logger = logger.With().Str("addr", redisAddr).Int("db", cfg.Redis.DB).Logger()
+	logger.Info().Msg("Redis: инициализация подключения")
 	
+	tlsCfg, err := cfg.Redis.TLS.ClientConfig()
+	if err != nil {
+		logger.Error().Err(err).Msg("Redis: ошибка настройки TLS")
+		return nil
+	}
+
 	client := redis.NewClient(&redis.Options{
-		Addr: redisAddr,
-		DB:   0,
+		Addr:      redisAddr,
+		Username:  cfg.Redis.Username,
+		Password:  cfg.Redis.Password,
+		DB:        cfg.Redis.DB,
+		TLSConfig: tlsCfg,
 	})
 
 	ctx := context.Background()
 	if err := client.Ping(ctx).Err(); err != nil {
-		log.Printf("Redis: ошибка подключения к Redis: %v", err)
+		logger.Error().Err(err).Msg("Redis: ошибка подключения")
 		return nil
 	}
-	log.Printf("Redis: успешно подключено к Redis по адресу %s", redisAddr)
+	logger.Info().Msg("Redis: успешно подключено")
+	return client
+}
 
+func InitCache(cfg *config.Config, client *redis.Client) *cache.Cache {
 	c := cache.NewCache(client, cfg.Redis.TTL)
 	return c
 }
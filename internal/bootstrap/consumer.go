@@ -0,0 +1,33 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/DmitriySama/teammate_search/config"
+	"github.com/DmitriySama/teammate_search/internal/consumer"
+	"github.com/DmitriySama/teammate_search/internal/storage/pgstorage"
+)
+
+func InitPopularityConsumer(cfg *config.Config, storage *pgstorage.PGstorage, logger zerolog.Logger) *consumer.PopularityConsumer {
+	opts := consumer.PopularityOptions{
+		HalfLife:      time.Duration(cfg.Popularity.HalfLifeHours) * time.Hour,
+		BatchSize:     cfg.Popularity.BatchSize,
+		FlushInterval: time.Duration(cfg.Popularity.FlushIntervalSeconds) * time.Second,
+		DecayInterval: time.Duration(cfg.Popularity.DecayIntervalMinutes) * time.Minute,
+	}
+
+	reader := consumer.NewReader(cfg, cfg.Topics.UserPopularity, mustKafkaDialer(cfg, logger), logger)
+	return consumer.NewPopularityConsumer(reader, storage, opts)
+}
+
+func InitTrendsConsumer(cfg *config.Config, storage *pgstorage.PGstorage, logger zerolog.Logger) *consumer.TrendsConsumer {
+	opts := consumer.TrendsOptions{
+		BatchSize:     cfg.Trends.BatchSize,
+		FlushInterval: time.Duration(cfg.Trends.FlushIntervalSeconds) * time.Second,
+	}
+
+	reader := consumer.NewReader(cfg, cfg.Topics.FilterData, mustKafkaDialer(cfg, logger), logger)
+	return consumer.NewTrendsConsumer(reader, storage, opts)
+}
@@ -0,0 +1,49 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/DmitriySama/teammate_search/config"
+	"github.com/DmitriySama/teammate_search/internal/cache"
+	"github.com/DmitriySama/teammate_search/internal/health"
+	"github.com/DmitriySama/teammate_search/internal/storage/pgstorage"
+)
+
+func InitHealth(cfg *config.Config, storage *pgstorage.PGstorage, cache *cache.Cache, logger zerolog.Logger) *health.Checker {
+	dialer := mustKafkaDialer(cfg, logger)
+	return health.NewChecker(time.Duration(cfg.Health.TimeoutMillis)*time.Millisecond,
+		health.Check{Name: "postgres", Ping: storage.Ping},
+		// Без Redis кеш отключается, а сессии хранятся в памяти процесса
+		health.Check{Name: "redis", Ping: cache.Ping, Optional: true},
+		health.Check{Name: "kafka", Ping: func(ctx context.Context) error {
+			return pingKafka(ctx, dialer, cfg.Kafka.Brokers)
+		}},
+	)
+}
+
+// pingKafka считает Kafka доступной, если отвечает хотя бы один брокер
+func pingKafka(ctx context.Context, dialer *kafka.Dialer, brokers []string) error {
+	var errs []error
+	for _, broker := range brokers {
+		conn, err := dialer.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		}
+		_, err = conn.Brokers()
+		conn.Close()
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
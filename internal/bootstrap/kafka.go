@@ -0,0 +1,58 @@
+package bootstrap
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+
+	"github.com/DmitriySama/teammate_search/config"
+)
+
+// mustKafkaDialer как kafkaDialer, но останавливает запуск при ошибке
+func mustKafkaDialer(cfg *config.Config, logger zerolog.Logger) *kafka.Dialer {
+	dialer, err := kafkaDialer(cfg)
+	if err != nil {
+		logger.Panic().Err(err).Msg("Ошибка настройки подключения к Kafka")
+	}
+	return dialer
+}
+
+// kafkaDialer подключение к брокерам с учётом kafka.tls и kafka.sasl, общее для writer'ов и reader'ов
+func kafkaDialer(cfg *config.Config) (*kafka.Dialer, error) {
+	tlsCfg, err := cfg.Kafka.TLS.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	mechanism, err := saslMechanism(cfg.Kafka.SASL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafka.Dialer{
+		ClientID:      cfg.ServiceName,
+		Timeout:       10 * time.Second,
+		DualStack:     true,
+		TLS:           tlsCfg,
+		SASLMechanism: mechanism,
+	}, nil
+}
+
+func saslMechanism(cfg config.SASLConfig) (sasl.Mechanism, error) {
+	switch cfg.Mechanism {
+	case "":
+		return nil, nil
+	case config.SASLPlain:
+		return plain.Mechanism{Username: cfg.Username, Password: cfg.Password}, nil
+	case config.SASLScramSHA256:
+		return scram.Mechanism(scram.SHA256, cfg.Username, cfg.Password)
+	case config.SASLScramSHA512:
+		return scram.Mechanism(scram.SHA512, cfg.Username, cfg.Password)
+	default:
+		return nil, fmt.Errorf("неизвестный SASL-механизм %q", cfg.Mechanism)
+	}
+}
@@ -0,0 +1,70 @@
+package bootstrap
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Lifecycle управляет фоновыми воркерами и ресурсами приложения.
+// При остановке воркеры получают отмену контекста, а ресурсы закрываются в порядке, обратном регистрации
+type Lifecycle struct {
+	logger  zerolog.Logger
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	closers []closer
+}
+
+type closer struct {
+	name  string
+	close func() error
+}
+
+// NewLifecycle создаёт жизненный цикл, воркеры получают контекст с logger
+func NewLifecycle(logger zerolog.Logger) *Lifecycle {
+	ctx, cancel := context.WithCancel(logger.WithContext(context.Background()))
+	return &Lifecycle{logger: logger, ctx: ctx, cancel: cancel}
+}
+
+// Go запускает воркер, run должен вернуться после отмены ctx
+func (l *Lifecycle) Go(name string, run func(ctx context.Context)) {
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		run(l.ctx)
+		l.logger.Info().Str("worker", name).Msg("Остановлен воркер")
+	}()
+}
+
+// OnStop регистрирует закрытие ресурса
+func (l *Lifecycle) OnStop(name string, close func() error) {
+	l.closers = append(l.closers, closer{name: name, close: close})
+}
+
+// Shutdown останавливает воркеры, ждёт их не дольше timeout и закрывает ресурсы
+func (l *Lifecycle) Shutdown(timeout time.Duration) {
+	l.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		l.logger.Warn().Dur("timeout", timeout).Msg("Не все воркеры остановились за отведённое время")
+	}
+
+	for i := len(l.closers) - 1; i >= 0; i-- {
+		c := l.closers[i]
+		if err := c.close(); err != nil {
+			l.logger.Error().Err(err).Str("resource", c.name).Msg("Ошибка закрытия")
+			continue
+		}
+		l.logger.Info().Str("resource", c.name).Msg("Закрыто")
+	}
+}
@@ -0,0 +1,51 @@
+package bootstrap
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+)
+
+type LifecycleSuite struct {
+	suite.Suite
+	lifecycle *Lifecycle
+}
+
+func (s *LifecycleSuite) SetupTest() {
+	s.lifecycle = NewLifecycle(zerolog.Nop())
+}
+
+func TestLifecycleSuite(t *testing.T) {
+	suite.Run(t, new(LifecycleSuite))
+}
+
+func (s *LifecycleSuite) TestShutdown_StopsWorkersBeforeClosingInReverse() {
+	var events []string
+	s.lifecycle.OnStop("db", func() error { events = append(events, "close db"); return nil })
+	s.lifecycle.OnStop("redis", func() error { events = append(events, "close redis"); return nil })
+	s.lifecycle.Go("worker", func(ctx context.Context) {
+		<-ctx.Done()
+		events = append(events, "worker stopped")
+	})
+
+	s.lifecycle.Shutdown(time.Second)
+
+	s.Equal([]string{"worker stopped", "close redis", "close db"}, events)
+}
+
+func (s *LifecycleSuite) TestShutdown_DoesNotHangOnStuckWorker() {
+	block := make(chan struct{})
+	defer close(block)
+	s.lifecycle.Go("stuck", func(context.Context) { <-block })
+	closed := false
+	s.lifecycle.OnStop("db", func() error { closed = true; return nil })
+
+	start := time.Now()
+	s.lifecycle.Shutdown(20 * time.Millisecond)
+
+	s.Less(time.Since(start), time.Second)
+	s.True(closed)
+}
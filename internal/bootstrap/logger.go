@@ -0,0 +1,22 @@
+package bootstrap
+
+import (
+	"os"
+
+	"github.com/rs/zerolog"
+
+	"github.com/DmitriySama/teammate_search/config"
+	"github.com/DmitriySama/teammate_search/internal/logging"
+)
+
+// InitLogger создаёт логгер приложения из cfg.Log. Он же становится логгером по умолчанию
+// для zerolog.Ctx, чтобы код без логгера в контексте не терял записи
+func InitLogger(cfg *config.Config) (zerolog.Logger, error) {
+	logger, err := logging.New(cfg.Log, os.Stdout)
+	if err != nil {
+		return logger, err
+	}
+	logger = logger.With().Str("service", cfg.ServiceName).Logger()
+	zerolog.DefaultContextLogger = &logger
+	return logger, nil
+}
@@ -1,27 +1,27 @@
 package bootstrap
 
 import (
-	"fmt"
-	"log"
+	"context"
+
+	"github.com/rs/zerolog"
 
 	"github.com/DmitriySama/teammate_search/config"
-	"github.com/DmitriySama/teammate_search/internal/producer"
 	"github.com/DmitriySama/teammate_search/internal/storage/pgstorage"
 )
 
-func InitPGStorage(cfg *config.Config, producer *producer.Manager) (*pgstorage.PGstorage) {
-
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-        cfg.Database.Host,
-        cfg.Database.Port,
-        cfg.Database.Username,
-        cfg.Database.Password,
-        cfg.Database.DBName)
-		
-	storage, err := pgstorage.InitDB(dsn, producer)
+func InitPGStorage(cfg *config.Config, logger zerolog.Logger) (*pgstorage.PGstorage) {
+	ctx := logger.WithContext(context.Background())
+	storage, err := pgstorage.InitDB(ctx, cfg.Database, cfg.Topics)
 	if err != nil {
-		log.Panic(fmt.Sprintf("ошибка инициализации БД, %v", err))
-		panic(err)
+		logger.Panic().Err(err).Msg("Ошибка инициализации БД")
+	}
+
+	if cfg.Database.AutoMigrate {
+		applied, err := storage.MigrateUp(ctx)
+		if err != nil {
+			logger.Panic().Err(err).Msg("Ошибка применения миграций")
+		}
+		logger.Info().Int("applied", applied).Msg("Применено миграций")
 	}
 	return storage
-}
\ No newline at end of file
+}
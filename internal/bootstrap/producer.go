@@ -1,11 +1,28 @@
 package bootstrap
 
 import (
+	"time"
+
+	"github.com/rs/zerolog"
+
 	"github.com/DmitriySama/teammate_search/config"
 	"github.com/DmitriySama/teammate_search/internal/producer"
+	"github.com/DmitriySama/teammate_search/internal/storage/pgstorage"
 )
 
-func InitProducers(cfg *config.Config) *producer.Manager {
-	return producer.NewManager(cfg)
+func InitProducers(cfg *config.Config, logger zerolog.Logger) *producer.Manager {
+	return producer.NewManager(cfg, mustKafkaDialer(cfg, logger), logger)
 }
 
+func InitOutboxRelay(cfg *config.Config, storage *pgstorage.PGstorage, manager *producer.Manager) *producer.Relay {
+	opts := producer.RelayOptions{
+		BatchSize:      cfg.Outbox.BatchSize,
+		PollInterval:   time.Duration(cfg.Outbox.PollIntervalMillis) * time.Millisecond,
+		PublishTimeout: time.Duration(cfg.Outbox.PublishTimeoutSeconds) * time.Second,
+		MinBackoff:     time.Duration(cfg.Outbox.MinBackoffSeconds) * time.Second,
+		MaxBackoff:     time.Duration(cfg.Outbox.MaxBackoffSeconds) * time.Second,
+		Lease:          time.Duration(cfg.Outbox.LeaseSeconds) * time.Second,
+	}
+
+	return producer.NewRelay(storage, manager, opts)
+}
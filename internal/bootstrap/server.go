@@ -2,18 +2,54 @@ package bootstrap
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
 
 	"github.com/DmitriySama/teammate_search/config"
 	"github.com/DmitriySama/teammate_search/internal/api/ts_service_api"
+	"github.com/DmitriySama/teammate_search/internal/health"
 )
 
-func AppRun(ctx context.Context, cfg *config.Config, api *ts_service_api.API) error   {
-    r := api.Router()
+// AppRun обслуживает HTTP до отмены ctx, затем переводит /readyz в 503, перестаёт принимать
+// соединения и ждёт завершения начатых запросов не дольше cfg.ShutdownTimeoutSeconds
+func AppRun(ctx context.Context, cfg *config.Config, api *ts_service_api.API, checker *health.Checker) error {
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           api.Router(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	logger := zerolog.Ctx(ctx)
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info().Str("addr", server.Addr).Msg("Сервер запущен")
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("ошибка HTTP-сервера: %w", err)
+	case <-ctx.Done():
+	}
+
+	checker.SetShuttingDown()
+	if delay := time.Duration(cfg.Health.ShutdownDelayMillis) * time.Millisecond; delay > 0 {
+		logger.Info().Dur("delay", delay).Msg("Ожидание перед остановкой HTTP-сервера")
+		time.Sleep(delay)
+	}
 
-    log.Println("Сервер запущен на http://localhost:3000")
-    
-    log.Fatal(http.ListenAndServe(":3000", r))
-    return nil
-}
\ No newline at end of file
+	logger.Info().Msg("Остановка HTTP-сервера")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeoutSeconds)*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("не удалось дождаться завершения запросов: %w", err)
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+
+	"github.com/DmitriySama/teammate_search/config"
+	"github.com/DmitriySama/teammate_search/internal/session"
+)
+
+func InitSessions(cfg *config.Config, client *redis.Client, logger zerolog.Logger) *session.Manager {
+	ttl := time.Duration(cfg.Session.TTL) * time.Second
+	if client == nil {
+		logger.Warn().Msg("Сессии: Redis недоступен, сессии будут храниться в памяти процесса")
+		return session.NewManager(session.NewMemoryStore(), ttl, cfg.Session.SecureCookie)
+	}
+	return session.NewManager(session.NewRedisStore(client), ttl, cfg.Session.SecureCookie)
+}
@@ -1,11 +1,26 @@
 package bootstrap
 
 import (
+	"github.com/DmitriySama/teammate_search/config"
 	"github.com/DmitriySama/teammate_search/internal/cache"
 	tsService "github.com/DmitriySama/teammate_search/internal/services/teammateSearchService"
 	"github.com/DmitriySama/teammate_search/internal/storage/pgstorage"
 )
 
-func InitTSService(storage *pgstorage.PGstorage, cache *cache.Cache) *tsService.Service {
-	return tsService.New(storage, cache)
+func InitTSService(cfg *config.Config, storage *pgstorage.PGstorage, cache *cache.Cache) *tsService.Service {
+	rc := cfg.Recommendations
+	opts := tsService.RecommendOptions{
+		Weights: tsService.RecommendWeights{
+			Game:       rc.GameWeight,
+			Genre:      rc.GenreWeight,
+			Language:   rc.LanguageWeight,
+			App:        rc.AppWeight,
+			Age:        rc.AgeWeight,
+			Popularity: rc.PopularityWeight,
+		},
+		AgeTolerance: rc.AgeToleranceYears,
+		Candidates:   rc.Candidates,
+		Limit:        rc.Limit,
+	}
+	return tsService.New(storage, cache, opts)
 }
@@ -1,10 +1,14 @@
 package bootstrap
 
 import (
+	"github.com/rs/zerolog"
+
 	"github.com/DmitriySama/teammate_search/internal/api/ts_service_api"
+	"github.com/DmitriySama/teammate_search/internal/health"
 	tsService "github.com/DmitriySama/teammate_search/internal/services/teammateSearchService"
+	"github.com/DmitriySama/teammate_search/internal/session"
 	"github.com/DmitriySama/teammate_search/internal/storage/pgstorage"
 )
-func InitRegistryAPI(service *tsService.Service, serviceName string, pg *pgstorage.PGstorage) *ts_service_api.API {
-	return ts_service_api.New(service, serviceName, pg)
-}
\ No newline at end of file
+func InitRegistryAPI(service *tsService.Service, serviceName string, pg *pgstorage.PGstorage, sessions *session.Manager, health *health.Checker, logger zerolog.Logger) *ts_service_api.API {
+	return ts_service_api.New(service, serviceName, pg, sessions, health, logger)
+}
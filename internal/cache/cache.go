@@ -3,11 +3,13 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"time"
+	"github.com/DmitriySama/teammate_search/internal/metrics"
 	"github.com/DmitriySama/teammate_search/internal/models"
 	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
 )
 
 type Cache struct {
@@ -36,6 +38,14 @@ func NewCache(client *redis.Client, ttlSeconds int) *Cache {
 	return &Cache{client: client, ttl:time.Duration(ttlSeconds) * time.Second,}
 }		
 
+// Ping проверяет соединение с Redis
+func (c *Cache) Ping(ctx context.Context) error {
+	if c == nil || c.client == nil {
+		return errors.New("Redis не подключён")
+	}
+	return c.client.Ping(ctx).Err()
+}
+
 func (c *Cache) Key(prefix, id string) string {
 	return fmt.Sprintf("%s:%s", prefix, id)
 }
@@ -43,55 +53,60 @@ func (c *Cache) Key(prefix, id string) string {
 
 func (c *Cache) GetLanguages(ctx context.Context) ([]models.Language, bool) {
 	if c == nil || c.client == nil {
+		metrics.CacheResult("languages", metrics.CacheMiss)
 		return nil, false
 	}
 
 	cacheKey := c.Key("languages", "all")
-	log.Printf("Redis: попытка получить языки из кэша для ключа %s", cacheKey)
+	zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Msg("Redis: попытка получить языки из кэша")
 
 	data, err := c.client.Get(ctx, cacheKey).Bytes()
 	if err != nil {
 		if err == redis.Nil {
-			log.Printf("Redis: языки не найдены в кэше для ключа %s", cacheKey)
+			metrics.CacheResult("languages", metrics.CacheMiss)
+			zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Msg("Redis: языки не найдены в кэше")
 		} else {
-			log.Printf("Redis: ошибка получения языков из кэша для ключа %s: %v", cacheKey, err)
+			metrics.CacheResult("languages", metrics.CacheError)
+			zerolog.Ctx(ctx).Warn().Err(err).Str("key", cacheKey).Msg("Redis: ошибка получения языков из кэша")
 		}
 		return nil, false
 	}
 
 	var cached LanguagesCache
 	if err := json.Unmarshal(data, &cached); err != nil {
-		log.Printf("Redis: ошибка десериализации языков из кэша для ключа %s: %v", cacheKey, err)
+		metrics.CacheResult("languages", metrics.CacheError)
+		zerolog.Ctx(ctx).Warn().Err(err).Str("key", cacheKey).Msg("Redis: ошибка десериализации языков из кэша")
 		return nil, false
 	}
 
-	log.Printf("Redis: успешно получены языки из кэша для ключа %s", cacheKey)
+	zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Msg("Redis: успешно получены языки из кэша")
+	metrics.CacheResult("languages", metrics.CacheHit)
 	return cached.Languages, true
 }
 
 
 func (c *Cache) SetLanguages(ctx context.Context, languages []models.Language) error {
 	if c == nil || c.client == nil {
-		log.Printf("Redis: кэш не инициализирован, пропуск сохранения языков")
+		zerolog.Ctx(ctx).Debug().Msg("Redis: кэш не инициализирован, пропуск сохранения языков")
 		return nil
 	}
 
 	cacheKey := c.Key("languages", "all")
-	log.Printf("Redis: попытка сохранить языки в кэше для ключа %s (TTL: %v)", cacheKey, c.ttl)
+	zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Dur("ttl", c.ttl).Msg("Redis: попытка сохранить языки в кэше")
 
 	value, err := json.Marshal(LanguagesCache{
 		Languages: languages,
 	})
 	if err != nil {
-		log.Printf("Redis: ошибка сериализации языков для ключа %s: %v", cacheKey, err)
+		zerolog.Ctx(ctx).Warn().Err(err).Str("key", cacheKey).Msg("Redis: ошибка сериализации языков")
 		return err
 	}
 
 	if err := c.client.Set(ctx, cacheKey, value, c.ttl).Err(); err != nil {
-		log.Printf("Redis: ошибка сохранения языков в кэше для ключа %s: %v", cacheKey, err)
+		zerolog.Ctx(ctx).Warn().Err(err).Str("key", cacheKey).Msg("Redis: ошибка сохранения языков в кэше")
 		return err
 	} else {
-		log.Printf("Redis: успешно сохранены языки в кэше для ключа %s", cacheKey)
+		zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Msg("Redis: успешно сохранены языки в кэше")
 	}
 	return nil
 }
@@ -99,54 +114,59 @@ func (c *Cache) SetLanguages(ctx context.Context, languages []models.Language) e
 
 func (c *Cache) GetGames(ctx context.Context) ([]models.Games, bool) {
 	if c == nil || c.client == nil {
+		metrics.CacheResult("games", metrics.CacheMiss)
 		return nil, false
 	}
 
 	cacheKey := c.Key("games", "all")
-	log.Printf("Redis: попытка получить игры из кэша для ключа %s", cacheKey)
+	zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Msg("Redis: попытка получить игры из кэша")
 
 	data, err := c.client.Get(ctx, cacheKey).Bytes()
 	if err != nil {
 		if err == redis.Nil {
-			log.Printf("Redis: игры не найдены в кэше для ключа %s", cacheKey)
+			metrics.CacheResult("games", metrics.CacheMiss)
+			zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Msg("Redis: игры не найдены в кэше")
 		} else {
-			log.Printf("Redis: ошибка получения игр из кэша для ключа %s: %v", cacheKey, err)
+			metrics.CacheResult("games", metrics.CacheError)
+			zerolog.Ctx(ctx).Warn().Err(err).Str("key", cacheKey).Msg("Redis: ошибка получения игр из кэша")
 		}
 		return nil, false
 	}
 
 	var cached GamesCache
 	if err := json.Unmarshal(data, &cached); err != nil {
-		log.Printf("Redis: ошибка десериализации игр из кэша для ключа %s: %v", cacheKey, err)
+		metrics.CacheResult("games", metrics.CacheError)
+		zerolog.Ctx(ctx).Warn().Err(err).Str("key", cacheKey).Msg("Redis: ошибка десериализации игр из кэша")
 		return nil, false
 	}
 
-	log.Printf("Redis: успешно получены игры из кэша для ключа %s", cacheKey)
+	zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Msg("Redis: успешно получены игры из кэша")
+	metrics.CacheResult("games", metrics.CacheHit)
 	return cached.Games, true
 }
 
 
 func (c *Cache) SetGames(ctx context.Context, games []models.Games)  error {
 	if c == nil || c.client == nil {
-		log.Printf("Redis: кэш не инициализирован, пропуск сохранения игр")
+		zerolog.Ctx(ctx).Debug().Msg("Redis: кэш не инициализирован, пропуск сохранения игр")
 		return nil
 	}
 
 	cacheKey := c.Key("games", "all")
-	log.Printf("Redis: попытка сохранить игры в кэше для ключа %s (TTL: %v)", cacheKey, c.ttl)
+	zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Dur("ttl", c.ttl).Msg("Redis: попытка сохранить игры в кэше")
 
 	value, err := json.Marshal(GamesCache{
 		Games:     games,
 	})
 	if err != nil {
-		log.Printf("Redis: ошибка сериализации игр для ключа %s: %v", cacheKey, err)
+		zerolog.Ctx(ctx).Warn().Err(err).Str("key", cacheKey).Msg("Redis: ошибка сериализации игр")
 		return err
 	}
 
 	if err := c.client.Set(ctx, cacheKey, value, c.ttl).Err(); err != nil {
-		log.Printf("Redis: ошибка сохранения игр в кэше для ключа %s: %v", cacheKey, err)
+		zerolog.Ctx(ctx).Warn().Err(err).Str("key", cacheKey).Msg("Redis: ошибка сохранения игр в кэше")
 	} else {
-		log.Printf("Redis: успешно сохранены игры в кэше для ключа %s", cacheKey)
+		zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Msg("Redis: успешно сохранены игры в кэше")
 	}
 	return nil
 }
@@ -154,108 +174,118 @@ func (c *Cache) SetGames(ctx context.Context, games []models.Games)  error {
 
 func (c *Cache) GetGenres(ctx context.Context) ([]models.Genres, bool) {
 	if c == nil || c.client == nil {
+		metrics.CacheResult("genres", metrics.CacheMiss)
 		return nil, false
 	}
 
 	cacheKey := c.Key("genres", "all")
-	log.Printf("Redis: попытка получить жанры игр из кэша для ключа %s", cacheKey)
+	zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Msg("Redis: попытка получить жанры игр из кэша")
 
 	data, err := c.client.Get(ctx, cacheKey).Bytes()
 	if err != nil {
 		if err == redis.Nil {
-			log.Printf("Redis: жанры игр не найдены в кэше для ключа %s", cacheKey)
+			metrics.CacheResult("genres", metrics.CacheMiss)
+			zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Msg("Redis: жанры игр не найдены в кэше")
 		} else {
-			log.Printf("Redis: ошибка получения жанров игр из кэша для ключа %s: %v", cacheKey, err)
+			metrics.CacheResult("genres", metrics.CacheError)
+			zerolog.Ctx(ctx).Warn().Err(err).Str("key", cacheKey).Msg("Redis: ошибка получения жанров игр из кэша")
 		}
 		return nil, false
 	}
 
 	var cached GenresCache
 	if err := json.Unmarshal(data, &cached); err != nil {
-		log.Printf("Redis: ошибка десериализации жанров игр из кэша для ключа %s: %v", cacheKey, err)
+		metrics.CacheResult("genres", metrics.CacheError)
+		zerolog.Ctx(ctx).Warn().Err(err).Str("key", cacheKey).Msg("Redis: ошибка десериализации жанров игр из кэша")
 		return nil, false
 	}
 
-	log.Printf("Redis: успешно получены жанры игр из кэша для ключа %s", cacheKey)
+	zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Msg("Redis: успешно получены жанры игр из кэша")
+	metrics.CacheResult("genres", metrics.CacheHit)
 	return cached.Genres, true
 }
 
 
 func (c *Cache) SetGenres(ctx context.Context, genres []models.Genres) error {
 	if c == nil || c.client == nil {
-		log.Printf("Redis: кэш не инициализирован, пропуск сохранения жанров игр")
+		zerolog.Ctx(ctx).Debug().Msg("Redis: кэш не инициализирован, пропуск сохранения жанров игр")
 		return nil
 	}
 
 	cacheKey := c.Key("genres", "all")
-	log.Printf("Redis: попытка сохранить жанры игр в кэше для ключа %s (TTL: %v)", cacheKey, c.ttl)
+	zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Dur("ttl", c.ttl).Msg("Redis: попытка сохранить жанры игр в кэше")
 
 	value, err := json.Marshal(GenresCache{
 		Genres:    genres,
 	})
 	if err != nil {
-		log.Printf("Redis: ошибка сериализации жанров игр для ключа %s: %v", cacheKey, err)
+		zerolog.Ctx(ctx).Warn().Err(err).Str("key", cacheKey).Msg("Redis: ошибка сериализации жанров игр")
 		return err
 	}
 
 	if err := c.client.Set(ctx, cacheKey, value, c.ttl).Err(); err != nil {
-		log.Printf("Redis: ошибка сохранения жанров игр в кэше для ключа %s: %v", cacheKey, err)
+		zerolog.Ctx(ctx).Warn().Err(err).Str("key", cacheKey).Msg("Redis: ошибка сохранения жанров игр в кэше")
 	} else {
-		log.Printf("Redis: успешно сохранены жанры игр в кэше для ключа %s", cacheKey)
+		zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Msg("Redis: успешно сохранены жанры игр в кэше")
 	}
 	return nil
 }
 
 func (c *Cache) GetApps(ctx context.Context) ([]models.Apps, bool) {
 	if c == nil || c.client == nil {
+		metrics.CacheResult("apps", metrics.CacheMiss)
 		return nil, false
 	}
 
 	cacheKey := c.Key("apps", "all")
-	log.Printf("Redis: попытка получить apps из кэша для ключа %s", cacheKey)
+	zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Msg("Redis: попытка получить apps из кэша")
 
 	data, err := c.client.Get(ctx, cacheKey).Bytes()
 	if err != nil {
 		if err == redis.Nil {
-			log.Printf("Redis: apps не найдены в кэше для ключа %s", cacheKey)
+			metrics.CacheResult("apps", metrics.CacheMiss)
+			zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Msg("Redis: apps не найдены в кэше")
 		} else {
-			log.Printf("Redis: ошибка получения apps из кэша для ключа %s: %v", cacheKey, err)
+			metrics.CacheResult("apps", metrics.CacheError)
+			zerolog.Ctx(ctx).Warn().Err(err).Str("key", cacheKey).Msg("Redis: ошибка получения apps из кэша")
 		}
 		return nil, false
 	}
 
 	var cached AppsCache
 	if err := json.Unmarshal(data, &cached); err != nil {
-		log.Printf("Redis: ошибка десериализации apps из кэша для ключа %s: %v", cacheKey, err)
+		metrics.CacheResult("apps", metrics.CacheError)
+		zerolog.Ctx(ctx).Warn().Err(err).Str("key", cacheKey).Msg("Redis: ошибка десериализации apps из кэша")
 		return nil, false
 	}
 
-	log.Printf("Redis: успешно получены apps из кэша для ключа %s", cacheKey)
+	zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Msg("Redis: успешно получены apps из кэша")
+	metrics.CacheResult("apps", metrics.CacheHit)
 	return cached.Apps, true
 }
 
 
 func (c *Cache) SetApps(ctx context.Context, apps []models.Apps) error{
 	if c == nil || c.client == nil {
-		log.Printf("Redis: кэш не инициализирован, пропуск сохранения apps")
+		zerolog.Ctx(ctx).Debug().Msg("Redis: кэш не инициализирован, пропуск сохранения apps")
 		return nil
 	}
 
 	cacheKey := c.Key("apps", "all")
-	log.Printf("Redis: попытка сохранить apps в кэше для ключа %s (TTL: %v)", cacheKey, c.ttl)
+	zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Dur("ttl", c.ttl).Msg("Redis: попытка сохранить apps в кэше")
 
 	value, err := json.Marshal(AppsCache{
 		Apps:    apps,
 	})
 	if err != nil {
-		log.Printf("Redis: ошибка сериализации apps для ключа %s: %v", cacheKey, err)
+		zerolog.Ctx(ctx).Warn().Err(err).Str("key", cacheKey).Msg("Redis: ошибка сериализации apps")
 		return err
 	}
 
 	if err := c.client.Set(ctx, cacheKey, value, c.ttl).Err(); err != nil {
-		log.Printf("Redis: ошибка сохранения apps в кэше для ключа %s: %v", cacheKey, err)
+		zerolog.Ctx(ctx).Warn().Err(err).Str("key", cacheKey).Msg("Redis: ошибка сохранения apps в кэше")
 	} else {
-		log.Printf("Redis: успешно сохранены apps в кэше для ключа %s", cacheKey)
+		zerolog.Ctx(ctx).Debug().Str("key", cacheKey).Msg("Redis: успешно сохранены apps в кэше")
 	}
 	return nil
 }
\ No newline at end of file
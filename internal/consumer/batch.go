@@ -0,0 +1,138 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/DmitriySama/teammate_search/internal/logging"
+)
+
+// batchHandler накапливает данные из сообщений и сохраняет их пачкой.
+// ctx в add несёт логгер с идентификатором запроса, породившего сообщение
+type batchHandler interface {
+	add(ctx context.Context, msg kafka.Message)
+	save(ctx context.Context) error
+	reset()
+}
+
+// Задержка перед повторным чтением после ошибки Kafka: растёт вдвое с каждой ошибкой подряд
+const (
+	fetchMinBackoff = 200 * time.Millisecond
+	fetchMaxBackoff = 30 * time.Second
+)
+
+// batchLoop общий цикл чтения топика: сообщения копятся до batchSize или до flushInterval,
+// затем пачка сохраняется и смещения коммитятся. Коммит только после успешного сохранения,
+// поэтому доставка at-least-once
+type batchLoop struct {
+	name          string
+	reader        MessageReader
+	handler       batchHandler
+	batchSize     int
+	flushInterval time.Duration
+	minBackoff    time.Duration
+	maxBackoff    time.Duration
+
+	pending []kafka.Message
+}
+
+// run читает топик до отмены ctx, затем сохраняет накопленную пачку и закрывает reader
+func (l *batchLoop) run(ctx context.Context) {
+	logger := zerolog.Ctx(ctx).With().Str("consumer", l.name).Logger()
+	ctx = logger.WithContext(ctx)
+	logger.Info().Msg("Kafka: запуск консьюмера")
+
+	failures := 0
+	for {
+		fetchCtx, cancel := context.WithTimeout(ctx, l.flushInterval)
+		msg, err := l.reader.FetchMessage(fetchCtx)
+		cancel()
+
+		if err != nil {
+			if ctx.Err() != nil {
+				shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+				l.flush(shutdownCtx)
+				cancel()
+				if err := l.reader.Close(); err != nil {
+					logger.Error().Err(err).Msg("Kafka: ошибка закрытия reader")
+				}
+				logger.Info().Msg("Kafka: консьюмер остановлен")
+				return
+			}
+			l.flush(ctx)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				failures++
+				delay := l.backoff(failures)
+				logger.Error().Err(err).Int("failures", failures).Dur("retry_in", delay).Msg("Kafka: ошибка чтения событий")
+				l.wait(ctx, delay)
+			}
+			continue
+		}
+
+		failures = 0
+		l.add(ctx, msg)
+		if len(l.pending) >= l.batchSize {
+			l.flush(ctx)
+		}
+	}
+}
+
+// backoff задержка после failures ошибок чтения подряд: minBackoff, 2×, 4×… но не больше maxBackoff
+func (l *batchLoop) backoff(failures int) time.Duration {
+	delay := l.minBackoff
+	for i := 1; i < failures && delay < l.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > l.maxBackoff {
+		delay = l.maxBackoff
+	}
+	return delay
+}
+
+// wait ждёт delay или отмены ctx
+func (l *batchLoop) wait(ctx context.Context, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+func (l *batchLoop) add(ctx context.Context, msg kafka.Message) {
+	l.pending = append(l.pending, msg)
+	l.handler.add(logging.WithRequestID(ctx, messageRequestID(msg)), msg)
+}
+
+// messageRequestID достаёт идентификатор запроса из заголовка X-Request-ID сообщения
+func messageRequestID(msg kafka.Message) string {
+	for _, header := range msg.Headers {
+		if header.Key == logging.RequestIDHeader {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+// flush сохраняет пачку и коммитит смещения.
+// При ошибке сохранения пачка остаётся и повторяется при следующем сбросе
+func (l *batchLoop) flush(ctx context.Context) {
+	if len(l.pending) == 0 {
+		return
+	}
+
+	if err := l.handler.save(ctx); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Int("events", len(l.pending)).Msg("Kafka: ошибка сохранения пачки")
+		return
+	}
+
+	if err := l.reader.CommitMessages(ctx, l.pending...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Kafka: ошибка коммита смещений")
+	}
+	l.pending = nil
+	l.handler.reset()
+}
@@ -0,0 +1,112 @@
+package consumer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/suite"
+)
+
+var errBrokerDown = errors.New("broker down")
+
+// scriptedReader отдаёт заранее заданные результаты, затем ошибку fallback или ждёт отмены
+type scriptedReader struct {
+	script   []error
+	fallback error
+	fetches  atomic.Int32
+}
+
+func (r *scriptedReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	n := int(r.fetches.Add(1)) - 1
+	if ctx.Err() != nil {
+		return kafka.Message{}, ctx.Err()
+	}
+	if n < len(r.script) {
+		return kafka.Message{Value: []byte("msg")}, r.script[n]
+	}
+	if r.fallback != nil {
+		return kafka.Message{}, r.fallback
+	}
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (r *scriptedReader) CommitMessages(context.Context, ...kafka.Message) error { return nil }
+
+func (r *scriptedReader) Close() error { return nil }
+
+type nopHandler struct{}
+
+func (nopHandler) add(context.Context, kafka.Message) {}
+func (nopHandler) save(context.Context) error         { return nil }
+func (nopHandler) reset()                             {}
+
+type BatchLoopSuite struct {
+	suite.Suite
+}
+
+func TestBatchLoopSuite(t *testing.T) {
+	suite.Run(t, new(BatchLoopSuite))
+}
+
+func newTestLoop(reader MessageReader, minBackoff, maxBackoff time.Duration) *batchLoop {
+	return &batchLoop{
+		name:          "test",
+		reader:        reader,
+		handler:       nopHandler{},
+		batchSize:     100,
+		flushInterval: time.Second,
+		minBackoff:    minBackoff,
+		maxBackoff:    maxBackoff,
+	}
+}
+
+func (s *BatchLoopSuite) TestBackoff_DoublesUpToMax() {
+	l := newTestLoop(nil, 200*time.Millisecond, time.Second)
+
+	s.Equal(200*time.Millisecond, l.backoff(1))
+	s.Equal(400*time.Millisecond, l.backoff(2))
+	s.Equal(800*time.Millisecond, l.backoff(3))
+	s.Equal(time.Second, l.backoff(4))
+	s.Equal(time.Second, l.backoff(40))
+}
+
+func (s *BatchLoopSuite) TestRun_BacksOffOnFetchErrors() {
+	reader := &scriptedReader{fallback: errBrokerDown}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	newTestLoop(reader, 20*time.Millisecond, 40*time.Millisecond).run(ctx)
+
+	// 20 + 40 + 40 мс ожидания: без задержки чтений были бы тысячи
+	s.LessOrEqual(reader.fetches.Load(), int32(6))
+	s.GreaterOrEqual(reader.fetches.Load(), int32(2))
+}
+
+func (s *BatchLoopSuite) TestRun_SuccessResetsBackoff() {
+	var logs bytes.Buffer
+	reader := &scriptedReader{script: []error{errBrokerDown, errBrokerDown, nil, errBrokerDown}}
+	ctx, cancel := context.WithTimeout(zerolog.New(&logs).WithContext(context.Background()), 200*time.Millisecond)
+	defer cancel()
+
+	newTestLoop(reader, time.Millisecond, time.Millisecond).run(ctx)
+
+	var failures []int
+	for _, line := range bytes.Split(bytes.TrimSpace(logs.Bytes()), []byte("\n")) {
+		var entry struct {
+			Failures int `json:"failures"`
+		}
+		s.Require().NoError(json.Unmarshal(line, &entry))
+		if entry.Failures > 0 {
+			failures = append(failures, entry.Failures)
+		}
+	}
+	s.Equal([]int{1, 2, 1}, failures)
+}
@@ -0,0 +1,102 @@
+package consumer
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/segmentio/kafka-go"
+)
+
+// PopularityStorage сохраняет счётчики популярности с затуханием
+type PopularityStorage interface {
+	AddPopularity(ctx context.Context, hits map[string]int, at time.Time, halfLife time.Duration) error
+	DecayPopularity(ctx context.Context, at time.Time, halfLife time.Duration) error
+}
+
+// PopularityConsumer читает события user.popularity (тело сообщения — username)
+// и пачками начисляет пользователям очки популярности
+type PopularityConsumer struct {
+	loop          *batchLoop
+	storage       PopularityStorage
+	halfLife      time.Duration
+	decayInterval time.Duration
+
+	hits map[string]int
+}
+
+type PopularityOptions struct {
+	HalfLife      time.Duration
+	BatchSize     int
+	FlushInterval time.Duration
+	DecayInterval time.Duration
+}
+
+func NewPopularityConsumer(reader MessageReader, storage PopularityStorage, opts PopularityOptions) *PopularityConsumer {
+	c := &PopularityConsumer{
+		storage:       storage,
+		halfLife:      opts.HalfLife,
+		decayInterval: opts.DecayInterval,
+		hits:          make(map[string]int),
+	}
+	c.loop = &batchLoop{
+		name:          "популярности",
+		reader:        reader,
+		handler:       c,
+		batchSize:     opts.BatchSize,
+		flushInterval: opts.FlushInterval,
+		minBackoff:    fetchMinBackoff,
+		maxBackoff:    fetchMaxBackoff,
+	}
+	return c
+}
+
+// Run читает топик до отмены ctx
+func (c *PopularityConsumer) Run(ctx context.Context) {
+	decayDone := make(chan struct{})
+	go func() {
+		defer close(decayDone)
+		c.runDecay(ctx)
+	}()
+
+	c.loop.run(ctx)
+	<-decayDone
+}
+
+func (c *PopularityConsumer) add(_ context.Context, msg kafka.Message) {
+	username := strings.TrimSpace(string(msg.Value))
+	if username == "" {
+		return
+	}
+	c.hits[username]++
+}
+
+func (c *PopularityConsumer) save(ctx context.Context) error {
+	if len(c.hits) == 0 {
+		return nil
+	}
+	return c.storage.AddPopularity(ctx, c.hits, time.Now(), c.halfLife)
+}
+
+func (c *PopularityConsumer) reset() {
+	c.hits = make(map[string]int)
+}
+
+// runDecay периодически пересчитывает затухание у всех пользователей,
+// чтобы сортировка по популярности сравнивала значения на один момент времени
+func (c *PopularityConsumer) runDecay(ctx context.Context) {
+	ticker := time.NewTicker(c.decayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := c.storage.DecayPopularity(ctx, now, c.halfLife); err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("Ошибка пересчёта затухания популярности")
+			}
+		}
+	}
+}
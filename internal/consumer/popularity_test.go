@@ -0,0 +1,126 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/DmitriySama/teammate_search/internal/logging"
+)
+
+type fakeReader struct {
+	committed []kafka.Message
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (r *fakeReader) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+	r.committed = append(r.committed, msgs...)
+	return nil
+}
+
+func (r *fakeReader) Close() error { return nil }
+
+type fakePopularityStorage struct {
+	hits []map[string]int
+	err  error
+}
+
+func (s *fakePopularityStorage) AddPopularity(_ context.Context, hits map[string]int, _ time.Time, _ time.Duration) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.hits = append(s.hits, hits)
+	return nil
+}
+
+func (s *fakePopularityStorage) DecayPopularity(context.Context, time.Time, time.Duration) error {
+	return nil
+}
+
+type PopularityConsumerSuite struct {
+	suite.Suite
+	ctx      context.Context
+	reader   *fakeReader
+	storage  *fakePopularityStorage
+	consumer *PopularityConsumer
+}
+
+func (s *PopularityConsumerSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.reader = &fakeReader{}
+	s.storage = &fakePopularityStorage{}
+	s.consumer = NewPopularityConsumer(s.reader, s.storage, PopularityOptions{
+		HalfLife:      time.Hour,
+		BatchSize:     10,
+		FlushInterval: time.Millisecond,
+		DecayInterval: time.Hour,
+	})
+}
+
+func TestPopularityConsumerSuite(t *testing.T) {
+	suite.Run(t, new(PopularityConsumerSuite))
+}
+
+func (s *PopularityConsumerSuite) TestFlush_AggregatesByUsername() {
+	s.consumer.loop.add(s.ctx, kafka.Message{Value: []byte("Aroman")})
+	s.consumer.loop.add(s.ctx, kafka.Message{Value: []byte("CRIGO")})
+	s.consumer.loop.add(s.ctx, kafka.Message{Value: []byte(" Aroman ")})
+	s.consumer.loop.add(s.ctx, kafka.Message{Value: []byte("")})
+
+	s.consumer.loop.flush(s.ctx)
+
+	s.Require().Len(s.storage.hits, 1)
+	s.Equal(map[string]int{"Aroman": 2, "CRIGO": 1}, s.storage.hits[0])
+	s.Len(s.reader.committed, 4)
+	s.Empty(s.consumer.loop.pending)
+}
+
+func (s *PopularityConsumerSuite) TestFlush_StorageErrorKeepsBatch() {
+	s.storage.err = errors.New("db down")
+	s.consumer.loop.add(s.ctx, kafka.Message{Value: []byte("Aroman")})
+
+	s.consumer.loop.flush(s.ctx)
+
+	s.Empty(s.reader.committed)
+	s.Len(s.consumer.loop.pending, 1)
+
+	s.storage.err = nil
+	s.consumer.loop.flush(s.ctx)
+
+	s.Len(s.reader.committed, 1)
+	s.Equal(map[string]int{"Aroman": 1}, s.storage.hits[0])
+}
+
+func (s *PopularityConsumerSuite) TestRun_StopsOnCancel() {
+	ctx, cancel := context.WithCancel(s.ctx)
+	done := make(chan struct{})
+	go func() {
+		s.consumer.Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		s.Fail("консьюмер не остановился после отмены контекста")
+	}
+}
+
+func (s *PopularityConsumerSuite) TestMessageRequestID_FromHeader() {
+	msg := kafka.Message{Headers: []kafka.Header{
+		{Key: "other", Value: []byte("x")},
+		{Key: logging.RequestIDHeader, Value: []byte("req-9")},
+	}}
+
+	s.Equal("req-9", messageRequestID(msg))
+	s.Empty(messageRequestID(kafka.Message{}))
+}
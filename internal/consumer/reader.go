@@ -0,0 +1,32 @@
+package consumer
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/DmitriySama/teammate_search/config"
+)
+
+// MessageReader часть kafka.Reader, которая нужна консьюмерам
+type MessageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// NewReader создаёт читателя топика в группе cfg.Kafka.GroupID
+func NewReader(cfg *config.Config, topic string, dialer *kafka.Dialer, logger zerolog.Logger) *kafka.Reader {
+	logger = logger.With().Str("component", "kafka-reader").Str("topic", topic).Logger()
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  cfg.Kafka.Brokers,
+		GroupID:  cfg.Kafka.GroupID,
+		Topic:    topic,
+		Dialer:   dialer,
+		MaxBytes: cfg.Kafka.MaxMessageBytes,
+		ErrorLogger: kafka.LoggerFunc(func(msg string, args ...interface{}) {
+			logger.Error().Msgf(msg, args...)
+		}),
+	})
+}
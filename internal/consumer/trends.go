@@ -0,0 +1,142 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+// anyFilterValue значение фильтра «Любой», в аналитику не попадает
+const anyFilterValue = "-1"
+
+// TrendsStorage сохраняет агрегаты поисковых запросов
+type TrendsStorage interface {
+	AddSearchTrends(ctx context.Context, counts map[models.TrendBucket]int) error
+}
+
+// TrendsConsumer читает события filter.data и сворачивает их
+// в почасовые и посуточные счётчики по каждому измерению фильтра
+type TrendsConsumer struct {
+	loop    *batchLoop
+	storage TrendsStorage
+
+	counts map[models.TrendBucket]int
+}
+
+type TrendsOptions struct {
+	BatchSize     int
+	FlushInterval time.Duration
+}
+
+func NewTrendsConsumer(reader MessageReader, storage TrendsStorage, opts TrendsOptions) *TrendsConsumer {
+	c := &TrendsConsumer{
+		storage: storage,
+		counts:  make(map[models.TrendBucket]int),
+	}
+	c.loop = &batchLoop{
+		name:          "аналитики поиска",
+		reader:        reader,
+		handler:       c,
+		batchSize:     opts.BatchSize,
+		flushInterval: opts.FlushInterval,
+		minBackoff:    fetchMinBackoff,
+		maxBackoff:    fetchMaxBackoff,
+	}
+	return c
+}
+
+// Run читает топик до отмены ctx
+func (c *TrendsConsumer) Run(ctx context.Context) {
+	c.loop.run(ctx)
+}
+
+func (c *TrendsConsumer) add(ctx context.Context, msg kafka.Message) {
+	var fd models.FilterData
+	if err := json.Unmarshal(msg.Value, &fd); err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("Kafka: пропуск некорректного события фильтра")
+		return
+	}
+
+	at := msg.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+	for dimension, values := range trendValues(fd) {
+		for _, value := range values {
+			for _, granularity := range []string{models.TrendHour, models.TrendDay} {
+				c.counts[models.TrendBucket{
+					Granularity: granularity,
+					Start:       bucketStart(at, granularity),
+					Dimension:   dimension,
+					Value:       value,
+				}]++
+			}
+		}
+	}
+}
+
+func (c *TrendsConsumer) save(ctx context.Context) error {
+	if len(c.counts) == 0 {
+		return nil
+	}
+	return c.storage.AddSearchTrends(ctx, c.counts)
+}
+
+func (c *TrendsConsumer) reset() {
+	c.counts = make(map[models.TrendBucket]int)
+}
+
+// trendValues выбирает из фильтра заданные пользователем значения измерений,
+// каждое из выбранных значений считается отдельным поиском этого значения
+func trendValues(fd models.FilterData) map[string][]string {
+	values := make(map[string][]string)
+	dictionaries := map[string]models.FilterValues{
+		models.TrendGame:     fd.Game,
+		models.TrendGenre:    fd.Genre,
+		models.TrendLanguage: fd.Language,
+		models.TrendApp:      fd.App,
+	}
+	for dimension, selected := range dictionaries {
+		for _, value := range selected {
+			value = strings.TrimSpace(value)
+			if value != "" && value != anyFilterValue && !slices.Contains(values[dimension], value) {
+				values[dimension] = append(values[dimension], value)
+			}
+		}
+	}
+	if ageRange := formatAgeRange(fd.Age0, fd.Age1); ageRange != "" {
+		values[models.TrendAgeRange] = []string{ageRange}
+	}
+	return values
+}
+
+// formatAgeRange записывает диапазон возраста как "18-30", открытые границы остаются пустыми: "18-", "-30"
+func formatAgeRange(from, to int) string {
+	if from <= 0 && to <= 0 {
+		return ""
+	}
+	var left, right string
+	if from > 0 {
+		left = fmt.Sprint(from)
+	}
+	if to > 0 {
+		right = fmt.Sprint(to)
+	}
+	return left + "-" + right
+}
+
+func bucketStart(at time.Time, granularity string) time.Time {
+	at = at.UTC()
+	if granularity == models.TrendDay {
+		return time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return at.Truncate(time.Hour)
+}
@@ -0,0 +1,88 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+type fakeTrendsStorage struct {
+	counts []map[models.TrendBucket]int
+}
+
+func (s *fakeTrendsStorage) AddSearchTrends(_ context.Context, counts map[models.TrendBucket]int) error {
+	s.counts = append(s.counts, counts)
+	return nil
+}
+
+type TrendsConsumerSuite struct {
+	suite.Suite
+	ctx      context.Context
+	reader   *fakeReader
+	storage  *fakeTrendsStorage
+	consumer *TrendsConsumer
+}
+
+func (s *TrendsConsumerSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.reader = &fakeReader{}
+	s.storage = &fakeTrendsStorage{}
+	s.consumer = NewTrendsConsumer(s.reader, s.storage, TrendsOptions{BatchSize: 10, FlushInterval: time.Millisecond})
+}
+
+func TestTrendsConsumerSuite(t *testing.T) {
+	suite.Run(t, new(TrendsConsumerSuite))
+}
+
+func (s *TrendsConsumerSuite) TestTrendValues_SkipsAny() {
+	values := trendValues(models.FilterData{
+		Age0:     18,
+		Game:     models.FilterValues{"2", "5", "2"},
+		Genre:    models.FilterValues{"-1"},
+		Language: models.FilterValues{""},
+		App:      models.FilterValues{"1"},
+	})
+
+	s.Equal(map[string][]string{
+		models.TrendGame:     {"2", "5"},
+		models.TrendApp:      {"1"},
+		models.TrendAgeRange: {"18-"},
+	}, values)
+}
+
+func (s *TrendsConsumerSuite) TestFilterData_ReadsLegacyStrings() {
+	var fd models.FilterData
+	s.Require().NoError(json.Unmarshal([]byte(`{"age0":18,"game":"2","genre":["1","3"]}`), &fd))
+
+	s.Equal(models.FilterValues{"2"}, fd.Game)
+	s.Equal(models.FilterValues{"1", "3"}, fd.Genre)
+}
+
+func (s *TrendsConsumerSuite) TestFormatAgeRange() {
+	s.Equal("", formatAgeRange(0, 0))
+	s.Equal("18-30", formatAgeRange(18, 30))
+	s.Equal("-30", formatAgeRange(0, 30))
+}
+
+func (s *TrendsConsumerSuite) TestFlush_HourlyAndDailyBuckets() {
+	at := time.Date(2026, 3, 14, 15, 42, 0, 0, time.UTC)
+	event := []byte(`{"age0":0,"age1":0,"game":"3","genre":"-1","language":"-1","app":"-1"}`)
+	s.consumer.loop.add(s.ctx, kafka.Message{Value: event, Time: at})
+	s.consumer.loop.add(s.ctx, kafka.Message{Value: event, Time: at.Add(10 * time.Minute)})
+	s.consumer.loop.add(s.ctx, kafka.Message{Value: []byte("not json"), Time: at})
+
+	s.consumer.loop.flush(s.ctx)
+
+	s.Require().Len(s.storage.counts, 1)
+	s.Equal(map[models.TrendBucket]int{
+		{Granularity: models.TrendHour, Start: time.Date(2026, 3, 14, 15, 0, 0, 0, time.UTC), Dimension: models.TrendGame, Value: "3"}: 2,
+		{Granularity: models.TrendDay, Start: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), Dimension: models.TrendGame, Value: "3"}:   2,
+	}, s.storage.counts[0])
+	s.Len(s.reader.committed, 3)
+}
@@ -0,0 +1,132 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Статусы проверок и сервиса в ответе /readyz
+const (
+	StatusUp           = "up"
+	StatusDown         = "down"
+	StatusOK           = "ok"
+	StatusDegraded     = "degraded"
+	StatusUnavailable  = "unavailable"
+	StatusShuttingDown = "shutting_down"
+)
+
+// Check проверка зависимости. Optional — без зависимости сервис работает в деградированном
+// режиме (например, без Redis), поэтому её недоступность не снимает готовность
+type Check struct {
+	Name     string
+	Ping     func(ctx context.Context) error
+	Optional bool
+}
+
+// CheckResult результат одной проверки. Текст ошибки содержит адреса и сообщения драйверов,
+// поэтому в ответ /readyz не попадает и пишется только в лог
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+// Report ответ /readyz
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Checker отвечает на пробы liveness и readiness
+type Checker struct {
+	checks       []Check
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{checks: checks, timeout: timeout}
+}
+
+// SetShuttingDown переводит /readyz в 503, чтобы балансировщик перестал слать запросы до остановки сервера
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Livez отвечает 200, пока процесс способен обрабатывать запросы; зависимости не проверяются
+func (c *Checker) Livez(w http.ResponseWriter, _ *http.Request) {
+	writeReport(w, http.StatusOK, Report{Status: StatusOK})
+}
+
+// Readyz параллельно проверяет зависимости, каждую не дольше timeout
+func (c *Checker) Readyz(w http.ResponseWriter, r *http.Request) {
+	if c.shuttingDown.Load() {
+		writeReport(w, http.StatusServiceUnavailable, Report{Status: StatusShuttingDown})
+		return
+	}
+
+	report := c.Check(r.Context())
+	status := http.StatusOK
+	if report.Status == StatusUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+	writeReport(w, status, report)
+}
+
+// Check выполняет все проверки и сводит их в общий статус
+func (c *Checker) Check(ctx context.Context) Report {
+	results := make([]CheckResult, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(c.checks))}
+	for i, check := range c.checks {
+		report.Checks[check.Name] = results[i]
+		if results[i].Status == StatusUp {
+			continue
+		}
+		if !check.Optional {
+			report.Status = StatusUnavailable
+		} else if report.Status == StatusOK {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Ping(ctx)
+	result := CheckResult{
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		zerolog.Ctx(ctx).Warn().Err(err).Str("check", check.Name).Float64("latency_ms", result.LatencyMs).
+			Msg("Проверка готовности: зависимость недоступна")
+	}
+	return result
+}
+
+func writeReport(w http.ResponseWriter, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,121 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+)
+
+type HealthSuite struct {
+	suite.Suite
+}
+
+func TestHealthSuite(t *testing.T) {
+	suite.Run(t, new(HealthSuite))
+}
+
+func ok(context.Context) error { return nil }
+
+func fail(context.Context) error { return errors.New("connection refused") }
+
+func (s *HealthSuite) readyz(checker *Checker) (int, Report) {
+	rec := httptest.NewRecorder()
+	checker.Readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report Report
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &report))
+	return rec.Code, report
+}
+
+func (s *HealthSuite) TestReadyz_AllUp() {
+	checker := NewChecker(time.Second, Check{Name: "postgres", Ping: ok}, Check{Name: "kafka", Ping: ok})
+
+	code, report := s.readyz(checker)
+
+	s.Equal(http.StatusOK, code)
+	s.Equal(StatusOK, report.Status)
+	s.Equal(StatusUp, report.Checks["postgres"].Status)
+	s.Equal(StatusUp, report.Checks["kafka"].Status)
+}
+
+func (s *HealthSuite) TestReadyz_RequiredDown() {
+	checker := NewChecker(time.Second, Check{Name: "postgres", Ping: fail}, Check{Name: "kafka", Ping: ok})
+
+	code, report := s.readyz(checker)
+
+	s.Equal(http.StatusServiceUnavailable, code)
+	s.Equal(StatusUnavailable, report.Status)
+	s.Equal(StatusDown, report.Checks["postgres"].Status)
+}
+
+func (s *HealthSuite) TestReadyz_ErrorOnlyInLog() {
+	var logs bytes.Buffer
+	checker := NewChecker(time.Second, Check{Name: "postgres", Ping: func(context.Context) error {
+		return errors.New("dial tcp 10.0.0.5:5432: connection refused")
+	}})
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	req = req.WithContext(zerolog.New(&logs).WithContext(req.Context()))
+	rec := httptest.NewRecorder()
+
+	checker.Readyz(rec, req)
+
+	s.Equal(http.StatusServiceUnavailable, rec.Code)
+	s.NotContains(rec.Body.String(), "10.0.0.5")
+	s.Contains(rec.Body.String(), `"latency_ms"`)
+	s.Contains(logs.String(), "10.0.0.5:5432")
+	s.Contains(logs.String(), `"check":"postgres"`)
+}
+
+func (s *HealthSuite) TestReadyz_OptionalDownIsDegraded() {
+	checker := NewChecker(time.Second, Check{Name: "postgres", Ping: ok}, Check{Name: "redis", Ping: fail, Optional: true})
+
+	code, report := s.readyz(checker)
+
+	s.Equal(http.StatusOK, code)
+	s.Equal(StatusDegraded, report.Status)
+}
+
+func (s *HealthSuite) TestReadyz_Timeout() {
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	checker := NewChecker(10*time.Millisecond, Check{Name: "kafka", Ping: hang})
+
+	code, report := s.readyz(checker)
+
+	s.Equal(http.StatusServiceUnavailable, code)
+	s.Equal(StatusDown, report.Checks["kafka"].Status)
+}
+
+func (s *HealthSuite) TestReadyz_ShuttingDown() {
+	called := false
+	checker := NewChecker(time.Second, Check{Name: "postgres", Ping: func(context.Context) error {
+		called = true
+		return nil
+	}})
+	checker.SetShuttingDown()
+
+	code, report := s.readyz(checker)
+
+	s.Equal(http.StatusServiceUnavailable, code)
+	s.Equal(StatusShuttingDown, report.Status)
+	s.False(called)
+}
+
+func (s *HealthSuite) TestLivez_IgnoresDependencies() {
+	checker := NewChecker(time.Second, Check{Name: "postgres", Ping: fail})
+	rec := httptest.NewRecorder()
+
+	checker.Livez(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+
+	s.Equal(http.StatusOK, rec.Code)
+}
@@ -0,0 +1,59 @@
+// Package logging настраивает zerolog и переносит идентификатор запроса через context:
+// HTTP-заголовок X-Request-ID → логгер запроса → outbox → заголовок сообщения Kafka → консьюмер
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/DmitriySama/teammate_search/config"
+)
+
+// Форматы вывода логов
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// New создаёт логгер с уровнем и форматом из cfg. Чувствительные поля (пароли, сессии, токены)
+// маскируются до записи в out
+func New(cfg config.LogConfig, out io.Writer) (zerolog.Logger, error) {
+	level, err := zerolog.ParseLevel(cfg.Level)
+	if err != nil {
+		return zerolog.Nop(), fmt.Errorf("некорректный уровень логирования %q: %w", cfg.Level, err)
+	}
+
+	switch cfg.Format {
+	case FormatJSON:
+	case FormatConsole:
+		out = zerolog.ConsoleWriter{Out: out, TimeFormat: time.RFC3339}
+	default:
+		return zerolog.Nop(), fmt.Errorf("неизвестный формат логов %q", cfg.Format)
+	}
+
+	return zerolog.New(&redactWriter{next: out}).
+		Level(level).
+		With().Timestamp().
+		Logger(), nil
+}
+
+type requestIDKey struct{}
+
+// WithRequestID сохраняет идентификатор запроса в ctx и добавляет поле request_id в логгер контекста
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	logger := zerolog.Ctx(ctx).With().Str("request_id", id).Logger()
+	return logger.WithContext(context.WithValue(ctx, requestIDKey{}, id))
+}
+
+// RequestID возвращает идентификатор запроса из ctx или пустую строку
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
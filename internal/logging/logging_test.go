@@ -0,0 +1,109 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/DmitriySama/teammate_search/config"
+)
+
+type LoggingSuite struct {
+	suite.Suite
+	out    *bytes.Buffer
+	logger zerolog.Logger
+}
+
+func (s *LoggingSuite) SetupTest() {
+	s.out = &bytes.Buffer{}
+	logger, err := New(config.LogConfig{Level: "debug", Format: FormatJSON}, s.out)
+	s.Require().NoError(err)
+	s.logger = logger
+}
+
+func TestLoggingSuite(t *testing.T) {
+	suite.Run(t, new(LoggingSuite))
+}
+
+// entries разбирает записанные JSON-строки логов
+func (s *LoggingSuite) entries() []map[string]any {
+	var result []map[string]any
+	decoder := json.NewDecoder(s.out)
+	for decoder.More() {
+		var entry map[string]any
+		s.Require().NoError(decoder.Decode(&entry))
+		result = append(result, entry)
+	}
+	return result
+}
+
+func (s *LoggingSuite) TestNew_RejectsUnknownSettings() {
+	_, err := New(config.LogConfig{Level: "verbose", Format: FormatJSON}, s.out)
+	s.Error(err)
+
+	_, err = New(config.LogConfig{Level: "info", Format: "xml"}, s.out)
+	s.Error(err)
+}
+
+func (s *LoggingSuite) TestRedact_SensitiveFields() {
+	s.logger.Info().
+		Str("username", "Aroman").
+		Str("password", "secret123").
+		Dict("request", zerolog.Dict().Str("Authorization", "Bearer abc")).
+		Msg("вход")
+
+	entries := s.entries()
+	s.Require().Len(entries, 1)
+	s.Equal("Aroman", entries[0]["username"])
+	s.Equal(Redacted, entries[0]["password"])
+	s.Equal(map[string]any{"Authorization": Redacted}, entries[0]["request"])
+	s.NotContains(s.out.String(), "secret123")
+}
+
+func (s *LoggingSuite) TestMiddleware_KeepsIncomingRequestID() {
+	var seen string
+	handler := Middleware(s.logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestID(r.Context())
+		zerolog.Ctx(r.Context()).Info().Msg("обработка")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/main/home", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	s.Equal("req-123", seen)
+	s.Equal("req-123", rec.Header().Get(RequestIDHeader))
+	entries := s.entries()
+	s.Require().Len(entries, 2)
+	for _, entry := range entries {
+		s.Equal("req-123", entry["request_id"])
+	}
+	s.Equal(float64(http.StatusOK), entries[1]["status"])
+}
+
+func (s *LoggingSuite) TestMiddleware_GeneratesRequestID() {
+	handler := Middleware(s.logger)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/main/home", nil)
+	req.Header.Set(RequestIDHeader, "bad id with spaces")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	id := rec.Header().Get(RequestIDHeader)
+	s.Len(id, 32)
+	s.NotEqual("bad id with spaces", id)
+}
+
+func (s *LoggingSuite) TestWithRequestID_Empty() {
+	ctx := context.Background()
+
+	s.Equal(ctx, WithRequestID(ctx, ""))
+	s.Empty(RequestID(ctx))
+}
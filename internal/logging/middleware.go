@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog"
+)
+
+// RequestIDHeader заголовок HTTP и сообщения Kafka с идентификатором запроса
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength ограничивает присланный клиентом идентификатор, чтобы он не раздувал логи
+const maxRequestIDLength = 128
+
+// quietPaths запросы проб и метрик, которые пишутся в журнал доступа только на уровне debug
+var quietPaths = map[string]bool{"/livez": true, "/readyz": true, "/health": true, "/metrics": true}
+
+// Middleware берёт идентификатор запроса из X-Request-ID или создаёт новый, возвращает его
+// в ответе, кладёт в контекст логгер с полем request_id и пишет запись журнала доступа
+func Middleware(logger zerolog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = NewRequestID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+
+			ctx := WithRequestID(logger.WithContext(r.Context()), id)
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			level := zerolog.InfoLevel
+			switch {
+			case status >= http.StatusInternalServerError:
+				level = zerolog.ErrorLevel
+			case quietPaths[r.URL.Path]:
+				level = zerolog.DebugLevel
+			}
+			zerolog.Ctx(ctx).WithLevel(level).
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Int("status", status).
+				Int("bytes", ww.BytesWritten()).
+				Dur("duration", time.Since(start)).
+				Msg("HTTP-запрос")
+		})
+	}
+}
+
+// NewRequestID создаёт случайный идентификатор запроса
+func NewRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID принимает только короткие идентификаторы из печатных ASCII-символов
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// Redacted значение, которое пишется вместо чувствительных полей
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys поля, значения которых не должны попадать в логи (сравнение без учёта регистра)
+var sensitiveKeys = []string{"password", "password_hash", "passwordhash", "session", "token", "authorization", "cookie", "secret"}
+
+// redactWriter маскирует чувствительные поля в JSON-записях zerolog. Записи без таких ключей
+// передаются как есть, поэтому разбор JSON стоит только тем строкам, где он нужен
+type redactWriter struct {
+	next io.Writer
+}
+
+func (w *redactWriter) Write(p []byte) (int, error) {
+	if !mayContainSensitive(p) {
+		return w.next.Write(p)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(p, &entry); err != nil {
+		return w.next.Write(p)
+	}
+	redactValue(entry)
+	out, err := json.Marshal(entry)
+	if err != nil {
+		return w.next.Write(p)
+	}
+	if _, err := w.next.Write(append(out, '\n')); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func mayContainSensitive(p []byte) bool {
+	lower := bytes.ToLower(p)
+	for _, key := range sensitiveKeys {
+		if bytes.Contains(lower, []byte(`"`+key+`"`)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range sensitiveKeys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
+func redactValue(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if isSensitive(key) {
+				v[key] = Redacted
+				continue
+			}
+			redactValue(value)
+		}
+	case []any:
+		for _, item := range v {
+			redactValue(item)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package metrics метрики Prometheus, которые сервис отдаёт на /metrics.
+//
+// HTTP:
+//
+//	teammate_http_requests_total{method, route, status}             число запросов по шаблону маршрута chi
+//	teammate_http_request_duration_seconds{method, route}           длительность обработки запроса
+//
+// PostgreSQL:
+//
+//	teammate_db_query_duration_seconds{method}                      длительность вызова метода PGstorage
+//
+// Кеш справочников в Redis:
+//
+//	teammate_cache_requests_total{dictionary, result}               чтения кеша, result: hit, miss, error
+//
+// Kafka:
+//
+//	teammate_kafka_publish_total{topic, result}                     отправки сообщений, result: success, failure
+//	teammate_kafka_publish_duration_seconds{topic}                  длительность отправки сообщения
+//
+// Дополнительно регистрируются стандартные метрики go_* и process_*.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "teammate"
+
+// Результаты чтения кеша
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheError = "error"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Число HTTP-запросов по методу, шаблону маршрута и статусу ответа.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Длительность обработки HTTP-запроса.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	dbDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Длительность вызова метода хранилища PostgreSQL.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"method"})
+
+	cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Чтения кеша справочников: hit, miss или error.",
+	}, []string{"dictionary", "result"})
+
+	kafkaPublish = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "publish_total",
+		Help:      "Отправки сообщений в Kafka по топику и результату.",
+	}, []string{"topic", "result"})
+
+	kafkaDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "publish_duration_seconds",
+		Help:      "Длительность отправки сообщения в Kafka.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"topic"})
+)
+
+// Handler отдаёт метрики в формате Prometheus
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// HTTPMiddleware считает запросы и их длительность. Маршрут берётся из шаблона chi
+// (/api/v1/search, а не конкретный URL), чтобы число серий не росло от параметров
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		httpRequests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		httpDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// ObserveDBQuery засекает время вызова метода хранилища: defer metrics.ObserveDBQuery("GetUserByID")()
+func ObserveDBQuery(method string) func() {
+	start := time.Now()
+	return func() {
+		dbDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	}
+}
+
+// CacheResult учитывает чтение справочника из кеша
+func CacheResult(dictionary, result string) {
+	cacheRequests.WithLabelValues(dictionary, result).Inc()
+}
+
+// ObserveKafkaPublish учитывает отправку сообщения в топик
+func ObserveKafkaPublish(topic string, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	kafkaPublish.WithLabelValues(topic, result).Inc()
+	kafkaDuration.WithLabelValues(topic).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/suite"
+)
+
+type MetricsSuite struct {
+	suite.Suite
+}
+
+func TestMetricsSuite(t *testing.T) {
+	suite.Run(t, new(MetricsSuite))
+}
+
+func (s *MetricsSuite) TestHTTPMiddleware_LabelsByRoutePattern() {
+	router := chi.NewRouter()
+	router.Use(HTTPMiddleware)
+	router.Get("/users/{username}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	counter := httpRequests.WithLabelValues(http.MethodGet, "/users/{username}", "418")
+	before := testutil.ToFloat64(counter)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/alice", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/bob", nil))
+
+	s.Equal(before+2, testutil.ToFloat64(counter))
+}
+
+func (s *MetricsSuite) TestHTTPMiddleware_Unmatched() {
+	router := chi.NewRouter()
+	router.Use(HTTPMiddleware)
+	router.Get("/known", func(http.ResponseWriter, *http.Request) {})
+	counter := httpRequests.WithLabelValues(http.MethodGet, "unmatched", "404")
+	before := testutil.ToFloat64(counter)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/random/path", nil))
+
+	s.Equal(before+1, testutil.ToFloat64(counter))
+}
+
+func (s *MetricsSuite) TestObserveKafkaPublish_Result() {
+	success := kafkaPublish.WithLabelValues("test.topic", "success")
+	failure := kafkaPublish.WithLabelValues("test.topic", "failure")
+	beforeSuccess, beforeFailure := testutil.ToFloat64(success), testutil.ToFloat64(failure)
+
+	ObserveKafkaPublish("test.topic", time.Now(), nil)
+	ObserveKafkaPublish("test.topic", time.Now(), errors.New("broker down"))
+
+	s.Equal(beforeSuccess+1, testutil.ToFloat64(success))
+	s.Equal(beforeFailure+1, testutil.ToFloat64(failure))
+}
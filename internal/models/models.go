@@ -1,30 +1,86 @@
 package models
 
 import (
+	"encoding/json"
+	"strconv"
 	"time"
 )
 
 type User struct {
     ID          int       `json:"id"`
 	Username    string    `json:"username"`
-	Password	string	  `json:"password"`
+	Password	string	  `json:"-"`
 	Age         int       `json:"age"`
     Description string    `json:"description"`
     MostLikeGame       string    `json:"mostlikegame"`
     MostLikeGenre       string    `json:"mostlikegenre"`
 	App			string 	  `json:"app"`
 	Language			string 	  `json:"language"`
+	Popularity	float64	  `json:"popularity"`
     CreatedAt   time.Time `json:"created_at"`
+	// Названия любимых значений справочников в порядке приоритета
+	Games       []string  `json:"games"`
+	Genres      []string  `json:"genres"`
+	Languages   []string  `json:"languages"`
+	Apps        []string  `json:"apps"`
+	Preferences
+	Privacy
 }
 
+// Privacy что скрыто от других игроков на публичной странице профиля, в поиске
+// и рекомендациях. Нулевое значение — профиль виден полностью
+type Privacy struct {
+	HideProfile    bool `json:"hide_profile"`
+	HideAge        bool `json:"hide_age"`
+	HidePopularity bool `json:"hide_popularity"`
+}
+
+// Update возвращает обновление, задающее все настройки приватности явно
+func (p Privacy) Update() PrivacyUpdate {
+	return PrivacyUpdate{HideProfile: &p.HideProfile, HideAge: &p.HideAge, HidePopularity: &p.HidePopularity}
+}
+
+// PrivacyUpdate новые настройки приватности, nil оставляет сохранённое значение
+type PrivacyUpdate struct {
+	HideProfile    *bool `json:"hide_profile"`
+	HideAge        *bool `json:"hide_age"`
+	HidePopularity *bool `json:"hide_popularity"`
+}
+
+// Apply возвращает p с заданными в обновлении значениями
+func (u PrivacyUpdate) Apply(p Privacy) Privacy {
+	if u.HideProfile != nil {
+		p.HideProfile = *u.HideProfile
+	}
+	if u.HideAge != nil {
+		p.HideAge = *u.HideAge
+	}
+	if u.HidePopularity != nil {
+		p.HidePopularity = *u.HidePopularity
+	}
+	return p
+}
+
+// Preferences любимые значения справочников: ID в порядке приоритета, первый — главный
+type Preferences struct {
+	GameIDs     []int `json:"game_ids"`
+	GenreIDs    []int `json:"genre_ids"`
+	LanguageIDs []int `json:"language_ids"`
+	AppIDs      []int `json:"app_ids"`
+}
+
+// UserUpdate новые значения профиля, справочники задаются ID (0 — не выбрано).
+// Одиночные ID — главные значения; если списки предпочтений не заданы,
+// они состоят из одного главного значения. Не заданные настройки приватности не меняются
 type UserUpdate struct {
-    ID          int       `json:"id"`
 	Age         int       `json:"age"`
     Description string    `json:"description"`
-    MostLikeGame        string    `json:"mostlikegame"`
-    MostLikeGenre       string    `json:"mostlikegenre"`
-	App			string 	  `json:"app"`
-	Language	string 	  `json:"language"`
+    GameID      int       `json:"game_id"`
+    GenreID     int       `json:"genre_id"`
+	AppID		int 	  `json:"app_id"`
+	LanguageID	int 	  `json:"language_id"`
+	Preferences
+	PrivacyUpdate
 }
 
 type UserListShow struct {
@@ -33,21 +89,78 @@ type UserListShow struct {
     Description string    `json:"description"`
     MostLikeGame        string    `json:"mostlikegame"`
     MostLikeGenre       string    `json:"mostlikegenre"`
+	App			string 	  `json:"app"`
 	Language	string 	  `json:"language"`
+	Popularity	float64	  `json:"popularity"`
+	// Snippet фрагмент описания с подсвеченными словами текстового запроса
+	Snippet     []TextFragment `json:"snippet,omitempty"`
 }
 
+// TextFragment часть текста, Match — совпадение с поисковым запросом
+type TextFragment struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match,omitempty"`
+}
+
+// Режимы совпадения списков значений фильтра
+const (
+	MatchAny = "any"
+	MatchAll = "all"
+)
+
 type FilterData struct {
 	Age0 int `json:"age0"`
 	Age1 int `json:"age1"`
-	Game string `json:"game"`
-	Genre string `json:"genre"`
-	Language string `json:"language"`
-	App string `json:"app"`
+	Game FilterValues `json:"game"`
+	Genre FilterValues `json:"genre"`
+	Language FilterValues `json:"language"`
+	App FilterValues `json:"app"`
+	// Match: any — у пользователя есть хотя бы одно из выбранных значений, all — все
+	Match string `json:"match,omitempty"`
+	// Query ключевые слова для полнотекстового поиска по описанию
+	Query string `json:"q,omitempty"`
+}
+
+// FilterValues выбранные в фильтре ID значений справочника. Из JSON читается
+// и массив, и одиночная строка из событий старого формата
+type FilterValues []string
+
+// Has сообщает, выбрано ли значение с этим ID, используется в шаблонах
+func (v FilterValues) Has(id int) bool {
+	for _, value := range v {
+		if value == strconv.Itoa(id) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *FilterValues) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*v = FilterValues{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*v = many
+	return nil
 }
 
+// UpdateUserDataVersion версия схемы события UpdateUserData, увеличивается при несовместимых изменениях
+const UpdateUserDataVersion = 1
+
+// UpdateUserData событие изменения профиля в топике UpdateUserData (ключ сообщения — ID пользователя).
+// Changed содержит JSON-имена изменившихся полей UserUpdate
 type UpdateUserData struct {
-	UserDataOld UserUpdate
-	UserDataNew UserUpdate
+	Version     int        `json:"version"`
+	UserID      int        `json:"user_id"`
+	ChangedAt   time.Time  `json:"changed_at"`
+	Changed     []string   `json:"changed"`
+	UserDataOld UserUpdate `json:"old"`
+	UserDataNew UserUpdate `json:"new"`
 }
 
 
@@ -71,3 +184,161 @@ type Apps struct {
 	App		string 	  `json:"app"`
 }
 
+
+// Порядки сортировки результатов поиска тиммейтов
+const (
+	SortNewest     = "newest"
+	SortAge        = "age"
+	SortPopularity = "popularity"
+	SortBestMatch  = "match"
+	// SortRelevance по релевантности описания текстовому запросу
+	SortRelevance  = "relevance"
+)
+
+// Ограничения размера страницы поиска
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// SearchRequest параметры постраничного поиска тиммейтов.
+// Cursor — непрозрачная строка из SearchPage предыдущего ответа
+type SearchRequest struct {
+	Filter   FilterData `json:"filter"`
+	Sort     string     `json:"sort"`
+	Cursor   string     `json:"cursor,omitempty"`
+	Limit    int        `json:"limit"`
+	ViewerID int        `json:"-"`
+}
+
+// SearchPage страница результатов поиска с курсорами соседних страниц
+type SearchPage struct {
+	Users      []UserListShow `json:"users"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	PrevCursor string         `json:"prev_cursor,omitempty"`
+}
+
+// Ограничения подсказок ников
+const (
+	UsernameSuggestMinLen  = 2
+	DefaultUsernameSuggest = 8
+	MaxUsernameSuggest     = 20
+)
+
+// UsernameSuggestion подсказка ника при вводе: ник и адрес аватара
+type UsernameSuggestion struct {
+	Username string `json:"username"`
+	Avatar   string `json:"avatar"`
+	Profile  string `json:"profile"`
+}
+
+// Recommendation подходящий тиммейт с оценкой совместимости и причинами совпадения
+type Recommendation struct {
+	User    UserListShow `json:"user"`
+	Score   int          `json:"score"` // совместимость в процентах
+	Reasons []string     `json:"reasons"`
+}
+
+// Состояние связи игрока с другим игроком
+const (
+	TeammateStatusNone      = "none"      // связи нет
+	TeammateStatusOutgoing  = "outgoing"  // игрок отправил заявку, ждёт ответа
+	TeammateStatusIncoming  = "incoming"  // другой игрок прислал заявку
+	TeammateStatusTeammates = "teammates" // заявка принята
+)
+
+// Действия с заявками в тиммейты, они же значения action в событиях TeammateEvent
+const (
+	TeammateActionRequest = "request" // отправить заявку
+	TeammateActionAccept  = "accept"  // принять входящую заявку
+	TeammateActionDecline = "decline" // отклонить входящую заявку
+	TeammateActionCancel  = "cancel"  // отозвать свою заявку
+	TeammateActionRemove  = "remove"  // удалить из тиммейтов
+)
+
+// TeammateRef участник заявки в тиммейты
+type TeammateRef struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+}
+
+// TeammateEventVersion версия схемы события TeammateEvent, увеличивается при несовместимых изменениях
+const TeammateEventVersion = 1
+
+// TeammateEvent событие в топике TeammateRequests (ключ сообщения — ID пары игроков,
+// чтобы события одной пары шли по порядку). Actor выполнил Action, Target — второй
+// игрок, которого стоит уведомить
+type TeammateEvent struct {
+	Version int         `json:"version"`
+	Action  string      `json:"action"`
+	Actor   TeammateRef `json:"actor"`
+	Target  TeammateRef `json:"target"`
+	At      time.Time   `json:"at"`
+}
+
+// Teammate игрок в списках заявок и тиммейтов. Since — время заявки, а для тиммейтов —
+// время её принятия
+type Teammate struct {
+	Username string    `json:"username"`
+	Avatar   string    `json:"avatar"`
+	Profile  string    `json:"profile"`
+	Since    time.Time `json:"since"`
+}
+
+// TeammateListLimit сколько последних записей отдаётся в каждом из списков TeammateLists
+const TeammateListLimit = 200
+
+// TeammateLists входящие и исходящие заявки и тиммейты игрока, новые первыми
+type TeammateLists struct {
+	Incoming  []Teammate `json:"incoming"`
+	Outgoing  []Teammate `json:"outgoing"`
+	Teammates []Teammate `json:"teammates"`
+}
+
+// Измерения аналитики поисковых запросов
+const (
+	TrendGame     = "game"
+	TrendGenre    = "genre"
+	TrendLanguage = "language"
+	TrendApp      = "app"
+	TrendAgeRange = "age_range"
+)
+
+// Гранулярность агрегатов аналитики
+const (
+	TrendHour = "hour"
+	TrendDay  = "day"
+)
+
+// TrendBucket ключ агрегата: сколько раз значение измерения искали за период
+type TrendBucket struct {
+	Granularity string
+	Start       time.Time
+	Dimension   string
+	Value       string
+}
+
+// TrendItem значение измерения с числом поисков за окно
+type TrendItem struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// Trending самые частые значения по каждому измерению за окно
+type Trending struct {
+	Granularity string                 `json:"granularity"`
+	Since       time.Time              `json:"since"`
+	Items       map[string][]TrendItem `json:"items"`
+}
+
+// OutboxEvent событие из таблицы outbox, ожидающее отправки в Kafka
+type OutboxEvent struct {
+	ID        int64
+	Topic     string
+	Key       string
+	Payload   []byte
+	Attempts  int
+	// RequestID идентификатор HTTP-запроса, породившего событие, пустой для фоновых событий
+	RequestID string
+}
@@ -2,70 +2,93 @@ package producer
 
 import (
 	"context"
-	"encoding/json"
-	"log"
+	"fmt"
 	"time"
-	"os"
+
+	"github.com/rs/zerolog"
 	"github.com/segmentio/kafka-go"
 
 	"github.com/DmitriySama/teammate_search/config"
-	"github.com/DmitriySama/teammate_search/internal/models"
+	"github.com/DmitriySama/teammate_search/internal/logging"
+	"github.com/DmitriySama/teammate_search/internal/metrics"
 )
 
+// writerBatchTimeout сколько writer ждёт добора пачки перед отправкой
+const writerBatchTimeout = 10 * time.Millisecond
+
+// Manager держит по writer'у на каждый топик, в который публикует приложение.
+// Вызывается только релеем outbox, обработчики запросов пишут события в БД
 type Manager struct {
-	filterTopic *kafka.Writer
-	userPopularityTopic *kafka.Writer
+	writers map[string]*kafka.Writer
 }
 
-func NewWriter(cfg *config.Config, topic string) *kafka.Writer {
+func NewWriter(cfg *config.Config, topic string, dialer *kafka.Dialer, logger zerolog.Logger) *kafka.Writer {
+	logger = logger.With().Str("component", "kafka-writer").Str("topic", topic).Logger()
 	writerCfg := kafka.WriterConfig{
-		Brokers:    cfg.Kafka.Brokers,
-		Topic:      topic,
-		Balancer:   &kafka.LeastBytes{},
+		Brokers: cfg.Kafka.Brokers,
+		Topic:   topic,
+		Dialer:  dialer,
+		// Сообщения с одинаковым ключом (например, ID пользователя) попадают в одну партицию
+		Balancer:   &kafka.Hash{},
 		BatchBytes: cfg.Kafka.MaxMessageBytes,
-		Logger:     log.New(os.Stdout, "KAFKA-WRITER: ", log.LstdFlags), // 🔥
-		ErrorLogger: log.New(os.Stderr, "KAFKA-WRITER-ERROR: ", log.LstdFlags), // 🔥
+		// Релей отправляет события синхронно по одному, и с BatchTimeout по умолчанию (1с)
+		// каждое сообщение ждало бы наполнения пачки
+		BatchTimeout: writerBatchTimeout,
+		Logger:       kafkaLogger(logger, zerolog.DebugLevel),
+		ErrorLogger:  kafkaLogger(logger, zerolog.ErrorLevel),
 	}
 
 	return kafka.NewWriter(writerCfg)
 }
 
-func NewManager(cfg *config.Config) *Manager {
-	return &Manager{
-		userPopularityTopic: NewWriter(cfg, "user.popularity"),
-		filterTopic: NewWriter(cfg, "filter.data"),
+// kafkaLogger направляет внутренние сообщения kafka-go в zerolog с заданным уровнем
+func kafkaLogger(logger zerolog.Logger, level zerolog.Level) kafka.LoggerFunc {
+	return func(msg string, args ...interface{}) {
+		logger.WithLevel(level).Msgf(msg, args...)
 	}
 }
 
-func (m *Manager) SendFilterData(ctx context.Context, response models.FilterData) error {
-	data, err := json.Marshal(response)
-	if err != nil {
-		log.Printf("Kafka: ошибка сериализации данных фильтрации %v", err)
-		return err
+// NewManager создаёт writer'ы для всех топиков из cfg.Topics
+func NewManager(cfg *config.Config, dialer *kafka.Dialer, logger zerolog.Logger) *Manager {
+	writers := map[string]*kafka.Writer{}
+	for _, topic := range []string{cfg.Topics.UserPopularity, cfg.Topics.FilterData, cfg.Topics.UpdateUserData, cfg.Topics.TeammateRequests} {
+		writers[topic] = NewWriter(cfg, topic, dialer, logger)
 	}
+	return &Manager{writers: writers}
+}
 
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
+// Publish синхронно отправляет сообщение в топик. Идентификатор запроса из ctx
+// передаётся в заголовке X-Request-ID
+func (m *Manager) Publish(ctx context.Context, topic, key string, payload []byte) error {
+	writer, ok := m.writers[topic]
+	if !ok {
+		return fmt.Errorf("неизвестный топик %s", topic)
+	}
 
-	log.Printf("Kafka: отправка ответа в топик %s", m.filterTopic.Topic)
-	if err := m.filterTopic.WriteMessages(ctx, kafka.Message{Value: data}); err != nil {
-		log.Printf("Kafka: ошибка отправки сообщения в топик %s: %v", m.filterTopic.Topic, err)
+	msg := kafka.Message{Value: payload}
+	if key != "" {
+		msg.Key = []byte(key)
+	}
+	if id := logging.RequestID(ctx); id != "" {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: logging.RequestIDHeader, Value: []byte(id)})
+	}
+	start := time.Now()
+	err := writer.WriteMessages(ctx, msg)
+	metrics.ObserveKafkaPublish(topic, start, err)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("topic", topic).Msg("Kafka: ошибка отправки сообщения")
 		return err
 	}
-
-	log.Printf("Kafka: данные фильтрации успешно отправлены")
 	return nil
 }
 
-
-func (m *Manager) SendUserPopularityData(ctx context.Context, username string) {
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
-
-	log.Printf("Kafka: отправка ответа в топик %s", m.userPopularityTopic.Topic,)
-	if err := m.userPopularityTopic.WriteMessages(ctx, kafka.Message{Value: []byte(username)}); err != nil {
-		log.Printf("Kafka: ошибка отправки сообщения в топик %s: %v", m.userPopularityTopic.Topic, err)
+// Close закрывает все writer'ы
+func (m *Manager) Close() error {
+	var firstErr error
+	for _, writer := range m.writers {
+		if err := writer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
-
-	log.Printf("Kafka: успешно отправлен ответ для приказа")
+	return firstErr
 }
@@ -0,0 +1,38 @@
+package producer
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/DmitriySama/teammate_search/config"
+)
+
+type ManagerSuite struct {
+	suite.Suite
+}
+
+func TestManagerSuite(t *testing.T) {
+	suite.Run(t, new(ManagerSuite))
+}
+
+func (s *ManagerSuite) TestNewManager_TopicsFromConfig() {
+	cfg := config.Defaults()
+	cfg.Kafka.Brokers = []string{"localhost:9092"}
+	cfg.Topics = config.TopicsConfig{
+		FilterData:       "stage.filter.data",
+		UserPopularity:   "stage.user.popularity",
+		UpdateUserData:   "stage.update.user.data",
+		TeammateRequests: "stage.teammate.requests",
+	}
+
+	manager := NewManager(&cfg, nil, zerolog.Nop())
+	defer manager.Close()
+
+	s.Len(manager.writers, 4)
+	for _, topic := range []string{cfg.Topics.FilterData, cfg.Topics.UserPopularity, cfg.Topics.UpdateUserData, cfg.Topics.TeammateRequests} {
+		s.Require().Contains(manager.writers, topic)
+		s.Equal(topic, manager.writers[topic].Topic)
+	}
+}
@@ -0,0 +1,102 @@
+package producer
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/DmitriySama/teammate_search/internal/logging"
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+// OutboxStore хранилище событий, ожидающих отправки
+type OutboxStore interface {
+	DrainOutbox(ctx context.Context, limit int, lease time.Duration, publish func(context.Context, models.OutboxEvent) error, backoff func(attempts int) time.Duration) (int, error)
+}
+
+// Publisher отправляет сообщение в Kafka
+type Publisher interface {
+	Publish(ctx context.Context, topic, key string, payload []byte) error
+}
+
+// RelayOptions настройки релея outbox
+type RelayOptions struct {
+	BatchSize      int
+	PollInterval   time.Duration
+	PublishTimeout time.Duration
+	MinBackoff     time.Duration
+	MaxBackoff     time.Duration
+	// Lease время, на которое захваченные события скрываются от других релеев.
+	// Должно с запасом покрывать отправку пачки
+	Lease time.Duration
+}
+
+// Relay переносит события из outbox в Kafka. Событие удаляется из outbox только после
+// успешной отправки, поэтому доставка at-least-once: консьюмеры должны переживать повторы
+type Relay struct {
+	store     OutboxStore
+	publisher Publisher
+	opts      RelayOptions
+}
+
+func NewRelay(store OutboxStore, publisher Publisher, opts RelayOptions) *Relay {
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		opts:      opts,
+	}
+}
+
+// Run опрашивает outbox до отмены ctx. Пока пачки приходят полными, следующая берётся сразу.
+// При остановке делает последний проход, чтобы отправить события последних запросов
+func (r *Relay) Run(ctx context.Context) {
+	logger := zerolog.Ctx(ctx).With().Str("component", "outbox-relay").Logger()
+	ctx = logger.WithContext(ctx)
+	logger.Info().Msg("Outbox: запуск релея")
+
+	for {
+		published, err := r.store.DrainOutbox(ctx, r.opts.BatchSize, r.opts.Lease, r.publish, r.backoff)
+		if err != nil && ctx.Err() == nil {
+			logger.Error().Err(err).Msg("Outbox: ошибка обработки событий")
+		}
+		if err == nil && published == r.opts.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			r.drainOnStop(ctx)
+			logger.Info().Msg("Outbox: остановка релея")
+			return
+		case <-time.After(r.opts.PollInterval):
+		}
+	}
+}
+
+// drainOnStop отправляет последнюю пачку уже после отмены parent, сохраняя его логгер
+func (r *Relay) drainOnStop(parent context.Context) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(parent), r.opts.PublishTimeout)
+	defer cancel()
+	if _, err := r.store.DrainOutbox(ctx, r.opts.BatchSize, r.opts.Lease, r.publish, r.backoff); err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("Outbox: события остались в outbox и будут отправлены после перезапуска")
+	}
+}
+
+func (r *Relay) publish(ctx context.Context, ev models.OutboxEvent) error {
+	ctx, cancel := context.WithTimeout(logging.WithRequestID(ctx, ev.RequestID), r.opts.PublishTimeout)
+	defer cancel()
+	return r.publisher.Publish(ctx, ev.Topic, ev.Key, ev.Payload)
+}
+
+// backoff экспоненциальная задержка перед повторной отправкой: MinBackoff, 2×, 4×… но не больше MaxBackoff
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.opts.MinBackoff
+	for i := 1; i < attempts && delay < r.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.opts.MaxBackoff {
+		delay = r.opts.MaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,56 @@
+package producer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/DmitriySama/teammate_search/internal/logging"
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+type RelaySuite struct {
+	suite.Suite
+	relay *Relay
+}
+
+func (s *RelaySuite) SetupTest() {
+	s.relay = NewRelay(nil, nil, RelayOptions{MinBackoff: time.Second, MaxBackoff: 10 * time.Second})
+}
+
+func TestRelaySuite(t *testing.T) {
+	suite.Run(t, new(RelaySuite))
+}
+
+func (s *RelaySuite) TestBackoff_Exponential() {
+	s.Equal(time.Second, s.relay.backoff(1))
+	s.Equal(2*time.Second, s.relay.backoff(2))
+	s.Equal(8*time.Second, s.relay.backoff(4))
+}
+
+func (s *RelaySuite) TestBackoff_Capped() {
+	s.Equal(10*time.Second, s.relay.backoff(5))
+	s.Equal(10*time.Second, s.relay.backoff(1000))
+}
+
+// requestIDPublisher запоминает идентификатор запроса из контекста отправки
+type requestIDPublisher struct {
+	requestID string
+}
+
+func (p *requestIDPublisher) Publish(ctx context.Context, _, _ string, _ []byte) error {
+	p.requestID = logging.RequestID(ctx)
+	return nil
+}
+
+func (s *RelaySuite) TestPublish_PropagatesRequestID() {
+	publisher := &requestIDPublisher{}
+	relay := NewRelay(nil, publisher, RelayOptions{PublishTimeout: time.Second})
+
+	err := relay.publish(context.Background(), models.OutboxEvent{Topic: "t", RequestID: "req-7"})
+
+	s.NoError(err)
+	s.Equal("req-7", publisher.requestID)
+}
@@ -4,15 +4,13 @@ package mocks
 
 import (
 	context "context"
-	http "net/http"
-
-	mock "github.com/stretchr/testify/mock"
 
 	models "github.com/DmitriySama/teammate_search/internal/models"
+	mock "github.com/stretchr/testify/mock"
 
 	pgstorage "github.com/DmitriySama/teammate_search/internal/storage/pgstorage"
 
-	sql "database/sql"
+	time "time"
 )
 
 // MockUsersStorage is an autogenerated mock type for the UsersStorage type
@@ -28,9 +26,153 @@ func (_m *MockUsersStorage) EXPECT() *MockUsersStorage_Expecter {
 	return &MockUsersStorage_Expecter{mock: &_m.Mock}
 }
 
-// FindUser provides a mock function with given fields: username, password
-func (_m *MockUsersStorage) FindUser(username string, password string) (int, error) {
-	ret := _m.Called(username, password)
+// AcceptTeammateRequest provides a mock function with given fields: ctx, user, from
+func (_m *MockUsersStorage) AcceptTeammateRequest(ctx context.Context, user models.TeammateRef, from models.TeammateRef) error {
+	ret := _m.Called(ctx, user, from)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AcceptTeammateRequest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.TeammateRef, models.TeammateRef) error); ok {
+		r0 = rf(ctx, user, from)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUsersStorage_AcceptTeammateRequest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AcceptTeammateRequest'
+type MockUsersStorage_AcceptTeammateRequest_Call struct {
+	*mock.Call
+}
+
+// AcceptTeammateRequest is a helper method to define mock.On call
+//   - ctx context.Context
+//   - user models.TeammateRef
+//   - from models.TeammateRef
+func (_e *MockUsersStorage_Expecter) AcceptTeammateRequest(ctx interface{}, user interface{}, from interface{}) *MockUsersStorage_AcceptTeammateRequest_Call {
+	return &MockUsersStorage_AcceptTeammateRequest_Call{Call: _e.mock.On("AcceptTeammateRequest", ctx, user, from)}
+}
+
+func (_c *MockUsersStorage_AcceptTeammateRequest_Call) Run(run func(ctx context.Context, user models.TeammateRef, from models.TeammateRef)) *MockUsersStorage_AcceptTeammateRequest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.TeammateRef), args[2].(models.TeammateRef))
+	})
+	return _c
+}
+
+func (_c *MockUsersStorage_AcceptTeammateRequest_Call) Return(_a0 error) *MockUsersStorage_AcceptTeammateRequest_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUsersStorage_AcceptTeammateRequest_Call) RunAndReturn(run func(context.Context, models.TeammateRef, models.TeammateRef) error) *MockUsersStorage_AcceptTeammateRequest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CancelTeammateRequest provides a mock function with given fields: ctx, user, to
+func (_m *MockUsersStorage) CancelTeammateRequest(ctx context.Context, user models.TeammateRef, to models.TeammateRef) error {
+	ret := _m.Called(ctx, user, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelTeammateRequest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.TeammateRef, models.TeammateRef) error); ok {
+		r0 = rf(ctx, user, to)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUsersStorage_CancelTeammateRequest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CancelTeammateRequest'
+type MockUsersStorage_CancelTeammateRequest_Call struct {
+	*mock.Call
+}
+
+// CancelTeammateRequest is a helper method to define mock.On call
+//   - ctx context.Context
+//   - user models.TeammateRef
+//   - to models.TeammateRef
+func (_e *MockUsersStorage_Expecter) CancelTeammateRequest(ctx interface{}, user interface{}, to interface{}) *MockUsersStorage_CancelTeammateRequest_Call {
+	return &MockUsersStorage_CancelTeammateRequest_Call{Call: _e.mock.On("CancelTeammateRequest", ctx, user, to)}
+}
+
+func (_c *MockUsersStorage_CancelTeammateRequest_Call) Run(run func(ctx context.Context, user models.TeammateRef, to models.TeammateRef)) *MockUsersStorage_CancelTeammateRequest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.TeammateRef), args[2].(models.TeammateRef))
+	})
+	return _c
+}
+
+func (_c *MockUsersStorage_CancelTeammateRequest_Call) Return(_a0 error) *MockUsersStorage_CancelTeammateRequest_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUsersStorage_CancelTeammateRequest_Call) RunAndReturn(run func(context.Context, models.TeammateRef, models.TeammateRef) error) *MockUsersStorage_CancelTeammateRequest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeclineTeammateRequest provides a mock function with given fields: ctx, user, from
+func (_m *MockUsersStorage) DeclineTeammateRequest(ctx context.Context, user models.TeammateRef, from models.TeammateRef) error {
+	ret := _m.Called(ctx, user, from)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeclineTeammateRequest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.TeammateRef, models.TeammateRef) error); ok {
+		r0 = rf(ctx, user, from)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUsersStorage_DeclineTeammateRequest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeclineTeammateRequest'
+type MockUsersStorage_DeclineTeammateRequest_Call struct {
+	*mock.Call
+}
+
+// DeclineTeammateRequest is a helper method to define mock.On call
+//   - ctx context.Context
+//   - user models.TeammateRef
+//   - from models.TeammateRef
+func (_e *MockUsersStorage_Expecter) DeclineTeammateRequest(ctx interface{}, user interface{}, from interface{}) *MockUsersStorage_DeclineTeammateRequest_Call {
+	return &MockUsersStorage_DeclineTeammateRequest_Call{Call: _e.mock.On("DeclineTeammateRequest", ctx, user, from)}
+}
+
+func (_c *MockUsersStorage_DeclineTeammateRequest_Call) Run(run func(ctx context.Context, user models.TeammateRef, from models.TeammateRef)) *MockUsersStorage_DeclineTeammateRequest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.TeammateRef), args[2].(models.TeammateRef))
+	})
+	return _c
+}
+
+func (_c *MockUsersStorage_DeclineTeammateRequest_Call) Return(_a0 error) *MockUsersStorage_DeclineTeammateRequest_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUsersStorage_DeclineTeammateRequest_Call) RunAndReturn(run func(context.Context, models.TeammateRef, models.TeammateRef) error) *MockUsersStorage_DeclineTeammateRequest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindUser provides a mock function with given fields: ctx, username, password
+func (_m *MockUsersStorage) FindUser(ctx context.Context, username string, password string) (int, error) {
+	ret := _m.Called(ctx, username, password)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindUser")
@@ -38,17 +180,17 @@ func (_m *MockUsersStorage) FindUser(username string, password string) (int, err
 
 	var r0 int
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) (int, error)); ok {
-		return rf(username, password)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (int, error)); ok {
+		return rf(ctx, username, password)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) int); ok {
-		r0 = rf(username, password)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int); ok {
+		r0 = rf(ctx, username, password)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(username, password)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, username, password)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -62,15 +204,16 @@ type MockUsersStorage_FindUser_Call struct {
 }
 
 // FindUser is a helper method to define mock.On call
+//   - ctx context.Context
 //   - username string
 //   - password string
-func (_e *MockUsersStorage_Expecter) FindUser(username interface{}, password interface{}) *MockUsersStorage_FindUser_Call {
-	return &MockUsersStorage_FindUser_Call{Call: _e.mock.On("FindUser", username, password)}
+func (_e *MockUsersStorage_Expecter) FindUser(ctx interface{}, username interface{}, password interface{}) *MockUsersStorage_FindUser_Call {
+	return &MockUsersStorage_FindUser_Call{Call: _e.mock.On("FindUser", ctx, username, password)}
 }
 
-func (_c *MockUsersStorage_FindUser_Call) Run(run func(username string, password string)) *MockUsersStorage_FindUser_Call {
+func (_c *MockUsersStorage_FindUser_Call) Run(run func(ctx context.Context, username string, password string)) *MockUsersStorage_FindUser_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
@@ -80,7 +223,7 @@ func (_c *MockUsersStorage_FindUser_Call) Return(_a0 int, _a1 error) *MockUsersS
 	return _c
 }
 
-func (_c *MockUsersStorage_FindUser_Call) RunAndReturn(run func(string, string) (int, error)) *MockUsersStorage_FindUser_Call {
+func (_c *MockUsersStorage_FindUser_Call) RunAndReturn(run func(context.Context, string, string) (int, error)) *MockUsersStorage_FindUser_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -317,9 +460,70 @@ func (_c *MockUsersStorage_GetLanguages_Call) RunAndReturn(run func(context.Cont
 	return _c
 }
 
-// GetUserByID provides a mock function with given fields: userID
-func (_m *MockUsersStorage) GetUserByID(userID int) (*models.User, error) {
-	ret := _m.Called(userID)
+// GetTrending provides a mock function with given fields: ctx, granularity, since, limit
+func (_m *MockUsersStorage) GetTrending(ctx context.Context, granularity string, since time.Time, limit int) (*models.Trending, error) {
+	ret := _m.Called(ctx, granularity, since, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTrending")
+	}
+
+	var r0 *models.Trending
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, int) (*models.Trending, error)); ok {
+		return rf(ctx, granularity, since, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, int) *models.Trending); ok {
+		r0 = rf(ctx, granularity, since, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Trending)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, int) error); ok {
+		r1 = rf(ctx, granularity, since, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUsersStorage_GetTrending_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTrending'
+type MockUsersStorage_GetTrending_Call struct {
+	*mock.Call
+}
+
+// GetTrending is a helper method to define mock.On call
+//   - ctx context.Context
+//   - granularity string
+//   - since time.Time
+//   - limit int
+func (_e *MockUsersStorage_Expecter) GetTrending(ctx interface{}, granularity interface{}, since interface{}, limit interface{}) *MockUsersStorage_GetTrending_Call {
+	return &MockUsersStorage_GetTrending_Call{Call: _e.mock.On("GetTrending", ctx, granularity, since, limit)}
+}
+
+func (_c *MockUsersStorage_GetTrending_Call) Run(run func(ctx context.Context, granularity string, since time.Time, limit int)) *MockUsersStorage_GetTrending_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockUsersStorage_GetTrending_Call) Return(_a0 *models.Trending, _a1 error) *MockUsersStorage_GetTrending_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUsersStorage_GetTrending_Call) RunAndReturn(run func(context.Context, string, time.Time, int) (*models.Trending, error)) *MockUsersStorage_GetTrending_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserByID provides a mock function with given fields: ctx, userID
+func (_m *MockUsersStorage) GetUserByID(ctx context.Context, userID int) (*models.User, error) {
+	ret := _m.Called(ctx, userID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetUserByID")
@@ -327,19 +531,19 @@ func (_m *MockUsersStorage) GetUserByID(userID int) (*models.User, error) {
 
 	var r0 *models.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int) (*models.User, error)); ok {
-		return rf(userID)
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.User, error)); ok {
+		return rf(ctx, userID)
 	}
-	if rf, ok := ret.Get(0).(func(int) *models.User); ok {
-		r0 = rf(userID)
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.User); ok {
+		r0 = rf(ctx, userID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(int) error); ok {
-		r1 = rf(userID)
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -353,14 +557,15 @@ type MockUsersStorage_GetUserByID_Call struct {
 }
 
 // GetUserByID is a helper method to define mock.On call
+//   - ctx context.Context
 //   - userID int
-func (_e *MockUsersStorage_Expecter) GetUserByID(userID interface{}) *MockUsersStorage_GetUserByID_Call {
-	return &MockUsersStorage_GetUserByID_Call{Call: _e.mock.On("GetUserByID", userID)}
+func (_e *MockUsersStorage_Expecter) GetUserByID(ctx interface{}, userID interface{}) *MockUsersStorage_GetUserByID_Call {
+	return &MockUsersStorage_GetUserByID_Call{Call: _e.mock.On("GetUserByID", ctx, userID)}
 }
 
-func (_c *MockUsersStorage_GetUserByID_Call) Run(run func(userID int)) *MockUsersStorage_GetUserByID_Call {
+func (_c *MockUsersStorage_GetUserByID_Call) Run(run func(ctx context.Context, userID int)) *MockUsersStorage_GetUserByID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int))
+		run(args[0].(context.Context), args[1].(int))
 	})
 	return _c
 }
@@ -370,7 +575,66 @@ func (_c *MockUsersStorage_GetUserByID_Call) Return(_a0 *models.User, _a1 error)
 	return _c
 }
 
-func (_c *MockUsersStorage_GetUserByID_Call) RunAndReturn(run func(int) (*models.User, error)) *MockUsersStorage_GetUserByID_Call {
+func (_c *MockUsersStorage_GetUserByID_Call) RunAndReturn(run func(context.Context, int) (*models.User, error)) *MockUsersStorage_GetUserByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserByUsername provides a mock function with given fields: ctx, username
+func (_m *MockUsersStorage) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	ret := _m.Called(ctx, username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserByUsername")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.User, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.User); ok {
+		r0 = rf(ctx, username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUsersStorage_GetUserByUsername_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserByUsername'
+type MockUsersStorage_GetUserByUsername_Call struct {
+	*mock.Call
+}
+
+// GetUserByUsername is a helper method to define mock.On call
+//   - ctx context.Context
+//   - username string
+func (_e *MockUsersStorage_Expecter) GetUserByUsername(ctx interface{}, username interface{}) *MockUsersStorage_GetUserByUsername_Call {
+	return &MockUsersStorage_GetUserByUsername_Call{Call: _e.mock.On("GetUserByUsername", ctx, username)}
+}
+
+func (_c *MockUsersStorage_GetUserByUsername_Call) Run(run func(ctx context.Context, username string)) *MockUsersStorage_GetUserByUsername_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUsersStorage_GetUserByUsername_Call) Return(_a0 *models.User, _a1 error) *MockUsersStorage_GetUserByUsername_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUsersStorage_GetUserByUsername_Call) RunAndReturn(run func(context.Context, string) (*models.User, error)) *MockUsersStorage_GetUserByUsername_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -430,29 +694,29 @@ func (_c *MockUsersStorage_GetUserCount_Call) RunAndReturn(run func() (int, erro
 	return _c
 }
 
-// GetUsers provides a mock function with given fields: r
-func (_m *MockUsersStorage) GetUsers(r *http.Request) (*sql.Rows, error) {
-	ret := _m.Called(r)
+// ListTeammates provides a mock function with given fields: ctx, userID, limit
+func (_m *MockUsersStorage) ListTeammates(ctx context.Context, userID int, limit int) (*models.TeammateLists, error) {
+	ret := _m.Called(ctx, userID, limit)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetUsers")
+		panic("no return value specified for ListTeammates")
 	}
 
-	var r0 *sql.Rows
+	var r0 *models.TeammateLists
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*http.Request) (*sql.Rows, error)); ok {
-		return rf(r)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (*models.TeammateLists, error)); ok {
+		return rf(ctx, userID, limit)
 	}
-	if rf, ok := ret.Get(0).(func(*http.Request) *sql.Rows); ok {
-		r0 = rf(r)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) *models.TeammateLists); ok {
+		r0 = rf(ctx, userID, limit)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*sql.Rows)
+			r0 = ret.Get(0).(*models.TeammateLists)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*http.Request) error); ok {
-		r1 = rf(r)
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userID, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -460,37 +724,39 @@ func (_m *MockUsersStorage) GetUsers(r *http.Request) (*sql.Rows, error) {
 	return r0, r1
 }
 
-// MockUsersStorage_GetUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUsers'
-type MockUsersStorage_GetUsers_Call struct {
+// MockUsersStorage_ListTeammates_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListTeammates'
+type MockUsersStorage_ListTeammates_Call struct {
 	*mock.Call
 }
 
-// GetUsers is a helper method to define mock.On call
-//   - r *http.Request
-func (_e *MockUsersStorage_Expecter) GetUsers(r interface{}) *MockUsersStorage_GetUsers_Call {
-	return &MockUsersStorage_GetUsers_Call{Call: _e.mock.On("GetUsers", r)}
+// ListTeammates is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - limit int
+func (_e *MockUsersStorage_Expecter) ListTeammates(ctx interface{}, userID interface{}, limit interface{}) *MockUsersStorage_ListTeammates_Call {
+	return &MockUsersStorage_ListTeammates_Call{Call: _e.mock.On("ListTeammates", ctx, userID, limit)}
 }
 
-func (_c *MockUsersStorage_GetUsers_Call) Run(run func(r *http.Request)) *MockUsersStorage_GetUsers_Call {
+func (_c *MockUsersStorage_ListTeammates_Call) Run(run func(ctx context.Context, userID int, limit int)) *MockUsersStorage_ListTeammates_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*http.Request))
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
 	})
 	return _c
 }
 
-func (_c *MockUsersStorage_GetUsers_Call) Return(_a0 *sql.Rows, _a1 error) *MockUsersStorage_GetUsers_Call {
+func (_c *MockUsersStorage_ListTeammates_Call) Return(_a0 *models.TeammateLists, _a1 error) *MockUsersStorage_ListTeammates_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockUsersStorage_GetUsers_Call) RunAndReturn(run func(*http.Request) (*sql.Rows, error)) *MockUsersStorage_GetUsers_Call {
+func (_c *MockUsersStorage_ListTeammates_Call) RunAndReturn(run func(context.Context, int, int) (*models.TeammateLists, error)) *MockUsersStorage_ListTeammates_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Login provides a mock function with given fields: username, password
-func (_m *MockUsersStorage) Login(username string, password string) (*pgstorage.AuthResult, error) {
-	ret := _m.Called(username, password)
+// Login provides a mock function with given fields: ctx, username, password
+func (_m *MockUsersStorage) Login(ctx context.Context, username string, password string) (*pgstorage.AuthResult, error) {
+	ret := _m.Called(ctx, username, password)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Login")
@@ -498,19 +764,19 @@ func (_m *MockUsersStorage) Login(username string, password string) (*pgstorage.
 
 	var r0 *pgstorage.AuthResult
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) (*pgstorage.AuthResult, error)); ok {
-		return rf(username, password)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*pgstorage.AuthResult, error)); ok {
+		return rf(ctx, username, password)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) *pgstorage.AuthResult); ok {
-		r0 = rf(username, password)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *pgstorage.AuthResult); ok {
+		r0 = rf(ctx, username, password)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*pgstorage.AuthResult)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(username, password)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, username, password)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -524,15 +790,16 @@ type MockUsersStorage_Login_Call struct {
 }
 
 // Login is a helper method to define mock.On call
+//   - ctx context.Context
 //   - username string
 //   - password string
-func (_e *MockUsersStorage_Expecter) Login(username interface{}, password interface{}) *MockUsersStorage_Login_Call {
-	return &MockUsersStorage_Login_Call{Call: _e.mock.On("Login", username, password)}
+func (_e *MockUsersStorage_Expecter) Login(ctx interface{}, username interface{}, password interface{}) *MockUsersStorage_Login_Call {
+	return &MockUsersStorage_Login_Call{Call: _e.mock.On("Login", ctx, username, password)}
 }
 
-func (_c *MockUsersStorage_Login_Call) Run(run func(username string, password string)) *MockUsersStorage_Login_Call {
+func (_c *MockUsersStorage_Login_Call) Run(run func(ctx context.Context, username string, password string)) *MockUsersStorage_Login_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
@@ -542,14 +809,74 @@ func (_c *MockUsersStorage_Login_Call) Return(_a0 *pgstorage.AuthResult, _a1 err
 	return _c
 }
 
-func (_c *MockUsersStorage_Login_Call) RunAndReturn(run func(string, string) (*pgstorage.AuthResult, error)) *MockUsersStorage_Login_Call {
+func (_c *MockUsersStorage_Login_Call) RunAndReturn(run func(context.Context, string, string) (*pgstorage.AuthResult, error)) *MockUsersStorage_Login_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecommendationCandidates provides a mock function with given fields: ctx, userID, limit
+func (_m *MockUsersStorage) RecommendationCandidates(ctx context.Context, userID int, limit int) ([]models.User, error) {
+	ret := _m.Called(ctx, userID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecommendationCandidates")
+	}
+
+	var r0 []models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]models.User, error)); ok {
+		return rf(ctx, userID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []models.User); ok {
+		r0 = rf(ctx, userID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUsersStorage_RecommendationCandidates_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecommendationCandidates'
+type MockUsersStorage_RecommendationCandidates_Call struct {
+	*mock.Call
+}
+
+// RecommendationCandidates is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - limit int
+func (_e *MockUsersStorage_Expecter) RecommendationCandidates(ctx interface{}, userID interface{}, limit interface{}) *MockUsersStorage_RecommendationCandidates_Call {
+	return &MockUsersStorage_RecommendationCandidates_Call{Call: _e.mock.On("RecommendationCandidates", ctx, userID, limit)}
+}
+
+func (_c *MockUsersStorage_RecommendationCandidates_Call) Run(run func(ctx context.Context, userID int, limit int)) *MockUsersStorage_RecommendationCandidates_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockUsersStorage_RecommendationCandidates_Call) Return(_a0 []models.User, _a1 error) *MockUsersStorage_RecommendationCandidates_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUsersStorage_RecommendationCandidates_Call) RunAndReturn(run func(context.Context, int, int) ([]models.User, error)) *MockUsersStorage_RecommendationCandidates_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Register provides a mock function with given fields: username, password, description, age
-func (_m *MockUsersStorage) Register(username string, password string, description string, age int) (*pgstorage.AuthResult, error) {
-	ret := _m.Called(username, password, description, age)
+// Register provides a mock function with given fields: ctx, username, password, description, age
+func (_m *MockUsersStorage) Register(ctx context.Context, username string, password string, description string, age int) (*pgstorage.AuthResult, error) {
+	ret := _m.Called(ctx, username, password, description, age)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Register")
@@ -557,19 +884,19 @@ func (_m *MockUsersStorage) Register(username string, password string, descripti
 
 	var r0 *pgstorage.AuthResult
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, string, int) (*pgstorage.AuthResult, error)); ok {
-		return rf(username, password, description, age)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, int) (*pgstorage.AuthResult, error)); ok {
+		return rf(ctx, username, password, description, age)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, string, int) *pgstorage.AuthResult); ok {
-		r0 = rf(username, password, description, age)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, int) *pgstorage.AuthResult); ok {
+		r0 = rf(ctx, username, password, description, age)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*pgstorage.AuthResult)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, string, int) error); ok {
-		r1 = rf(username, password, description, age)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, int) error); ok {
+		r1 = rf(ctx, username, password, description, age)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -583,17 +910,18 @@ type MockUsersStorage_Register_Call struct {
 }
 
 // Register is a helper method to define mock.On call
+//   - ctx context.Context
 //   - username string
 //   - password string
 //   - description string
 //   - age int
-func (_e *MockUsersStorage_Expecter) Register(username interface{}, password interface{}, description interface{}, age interface{}) *MockUsersStorage_Register_Call {
-	return &MockUsersStorage_Register_Call{Call: _e.mock.On("Register", username, password, description, age)}
+func (_e *MockUsersStorage_Expecter) Register(ctx interface{}, username interface{}, password interface{}, description interface{}, age interface{}) *MockUsersStorage_Register_Call {
+	return &MockUsersStorage_Register_Call{Call: _e.mock.On("Register", ctx, username, password, description, age)}
 }
 
-func (_c *MockUsersStorage_Register_Call) Run(run func(username string, password string, description string, age int)) *MockUsersStorage_Register_Call {
+func (_c *MockUsersStorage_Register_Call) Run(run func(ctx context.Context, username string, password string, description string, age int)) *MockUsersStorage_Register_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string), args[2].(string), args[3].(int))
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].(int))
 	})
 	return _c
 }
@@ -603,22 +931,189 @@ func (_c *MockUsersStorage_Register_Call) Return(_a0 *pgstorage.AuthResult, _a1
 	return _c
 }
 
-func (_c *MockUsersStorage_Register_Call) RunAndReturn(run func(string, string, string, int) (*pgstorage.AuthResult, error)) *MockUsersStorage_Register_Call {
+func (_c *MockUsersStorage_Register_Call) RunAndReturn(run func(context.Context, string, string, string, int) (*pgstorage.AuthResult, error)) *MockUsersStorage_Register_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// UpdateUser provides a mock function with given fields: r, user
-func (_m *MockUsersStorage) UpdateUser(r *http.Request, user models.User) error {
-	ret := _m.Called(r, user)
+// RemoveTeammate provides a mock function with given fields: ctx, user, other
+func (_m *MockUsersStorage) RemoveTeammate(ctx context.Context, user models.TeammateRef, other models.TeammateRef) error {
+	ret := _m.Called(ctx, user, other)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateUser")
+		panic("no return value specified for RemoveTeammate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.TeammateRef, models.TeammateRef) error); ok {
+		r0 = rf(ctx, user, other)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUsersStorage_RemoveTeammate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveTeammate'
+type MockUsersStorage_RemoveTeammate_Call struct {
+	*mock.Call
+}
+
+// RemoveTeammate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - user models.TeammateRef
+//   - other models.TeammateRef
+func (_e *MockUsersStorage_Expecter) RemoveTeammate(ctx interface{}, user interface{}, other interface{}) *MockUsersStorage_RemoveTeammate_Call {
+	return &MockUsersStorage_RemoveTeammate_Call{Call: _e.mock.On("RemoveTeammate", ctx, user, other)}
+}
+
+func (_c *MockUsersStorage_RemoveTeammate_Call) Run(run func(ctx context.Context, user models.TeammateRef, other models.TeammateRef)) *MockUsersStorage_RemoveTeammate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.TeammateRef), args[2].(models.TeammateRef))
+	})
+	return _c
+}
+
+func (_c *MockUsersStorage_RemoveTeammate_Call) Return(_a0 error) *MockUsersStorage_RemoveTeammate_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUsersStorage_RemoveTeammate_Call) RunAndReturn(run func(context.Context, models.TeammateRef, models.TeammateRef) error) *MockUsersStorage_RemoveTeammate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SearchUsernames provides a mock function with given fields: ctx, query, limit
+func (_m *MockUsersStorage) SearchUsernames(ctx context.Context, query string, limit int) ([]string, error) {
+	ret := _m.Called(ctx, query, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchUsernames")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]string, error)); ok {
+		return rf(ctx, query, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []string); ok {
+		r0 = rf(ctx, query, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, query, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUsersStorage_SearchUsernames_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchUsernames'
+type MockUsersStorage_SearchUsernames_Call struct {
+	*mock.Call
+}
+
+// SearchUsernames is a helper method to define mock.On call
+//   - ctx context.Context
+//   - query string
+//   - limit int
+func (_e *MockUsersStorage_Expecter) SearchUsernames(ctx interface{}, query interface{}, limit interface{}) *MockUsersStorage_SearchUsernames_Call {
+	return &MockUsersStorage_SearchUsernames_Call{Call: _e.mock.On("SearchUsernames", ctx, query, limit)}
+}
+
+func (_c *MockUsersStorage_SearchUsernames_Call) Run(run func(ctx context.Context, query string, limit int)) *MockUsersStorage_SearchUsernames_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockUsersStorage_SearchUsernames_Call) Return(_a0 []string, _a1 error) *MockUsersStorage_SearchUsernames_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUsersStorage_SearchUsernames_Call) RunAndReturn(run func(context.Context, string, int) ([]string, error)) *MockUsersStorage_SearchUsernames_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SearchUsers provides a mock function with given fields: ctx, req
+func (_m *MockUsersStorage) SearchUsers(ctx context.Context, req models.SearchRequest) (*models.SearchPage, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchUsers")
+	}
+
+	var r0 *models.SearchPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.SearchRequest) (*models.SearchPage, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.SearchRequest) *models.SearchPage); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SearchPage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.SearchRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUsersStorage_SearchUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchUsers'
+type MockUsersStorage_SearchUsers_Call struct {
+	*mock.Call
+}
+
+// SearchUsers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req models.SearchRequest
+func (_e *MockUsersStorage_Expecter) SearchUsers(ctx interface{}, req interface{}) *MockUsersStorage_SearchUsers_Call {
+	return &MockUsersStorage_SearchUsers_Call{Call: _e.mock.On("SearchUsers", ctx, req)}
+}
+
+func (_c *MockUsersStorage_SearchUsers_Call) Run(run func(ctx context.Context, req models.SearchRequest)) *MockUsersStorage_SearchUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.SearchRequest))
+	})
+	return _c
+}
+
+func (_c *MockUsersStorage_SearchUsers_Call) Return(_a0 *models.SearchPage, _a1 error) *MockUsersStorage_SearchUsers_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUsersStorage_SearchUsers_Call) RunAndReturn(run func(context.Context, models.SearchRequest) (*models.SearchPage, error)) *MockUsersStorage_SearchUsers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SendTeammateRequest provides a mock function with given fields: ctx, from, to
+func (_m *MockUsersStorage) SendTeammateRequest(ctx context.Context, from models.TeammateRef, to models.TeammateRef) error {
+	ret := _m.Called(ctx, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendTeammateRequest")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*http.Request, models.User) error); ok {
-		r0 = rf(r, user)
+	if rf, ok := ret.Get(0).(func(context.Context, models.TeammateRef, models.TeammateRef) error); ok {
+		r0 = rf(ctx, from, to)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -626,38 +1121,157 @@ func (_m *MockUsersStorage) UpdateUser(r *http.Request, user models.User) error
 	return r0
 }
 
+// MockUsersStorage_SendTeammateRequest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendTeammateRequest'
+type MockUsersStorage_SendTeammateRequest_Call struct {
+	*mock.Call
+}
+
+// SendTeammateRequest is a helper method to define mock.On call
+//   - ctx context.Context
+//   - from models.TeammateRef
+//   - to models.TeammateRef
+func (_e *MockUsersStorage_Expecter) SendTeammateRequest(ctx interface{}, from interface{}, to interface{}) *MockUsersStorage_SendTeammateRequest_Call {
+	return &MockUsersStorage_SendTeammateRequest_Call{Call: _e.mock.On("SendTeammateRequest", ctx, from, to)}
+}
+
+func (_c *MockUsersStorage_SendTeammateRequest_Call) Run(run func(ctx context.Context, from models.TeammateRef, to models.TeammateRef)) *MockUsersStorage_SendTeammateRequest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.TeammateRef), args[2].(models.TeammateRef))
+	})
+	return _c
+}
+
+func (_c *MockUsersStorage_SendTeammateRequest_Call) Return(_a0 error) *MockUsersStorage_SendTeammateRequest_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUsersStorage_SendTeammateRequest_Call) RunAndReturn(run func(context.Context, models.TeammateRef, models.TeammateRef) error) *MockUsersStorage_SendTeammateRequest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TeammateStatus provides a mock function with given fields: ctx, userID, otherID
+func (_m *MockUsersStorage) TeammateStatus(ctx context.Context, userID int, otherID int) (string, error) {
+	ret := _m.Called(ctx, userID, otherID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TeammateStatus")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (string, error)); ok {
+		return rf(ctx, userID, otherID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) string); ok {
+		r0 = rf(ctx, userID, otherID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userID, otherID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUsersStorage_TeammateStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TeammateStatus'
+type MockUsersStorage_TeammateStatus_Call struct {
+	*mock.Call
+}
+
+// TeammateStatus is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - otherID int
+func (_e *MockUsersStorage_Expecter) TeammateStatus(ctx interface{}, userID interface{}, otherID interface{}) *MockUsersStorage_TeammateStatus_Call {
+	return &MockUsersStorage_TeammateStatus_Call{Call: _e.mock.On("TeammateStatus", ctx, userID, otherID)}
+}
+
+func (_c *MockUsersStorage_TeammateStatus_Call) Run(run func(ctx context.Context, userID int, otherID int)) *MockUsersStorage_TeammateStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockUsersStorage_TeammateStatus_Call) Return(_a0 string, _a1 error) *MockUsersStorage_TeammateStatus_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUsersStorage_TeammateStatus_Call) RunAndReturn(run func(context.Context, int, int) (string, error)) *MockUsersStorage_TeammateStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateUser provides a mock function with given fields: ctx, userID, upd
+func (_m *MockUsersStorage) UpdateUser(ctx context.Context, userID int, upd models.UserUpdate) (*models.User, error) {
+	ret := _m.Called(ctx, userID, upd)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUser")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, models.UserUpdate) (*models.User, error)); ok {
+		return rf(ctx, userID, upd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, models.UserUpdate) *models.User); ok {
+		r0 = rf(ctx, userID, upd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, models.UserUpdate) error); ok {
+		r1 = rf(ctx, userID, upd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // MockUsersStorage_UpdateUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateUser'
 type MockUsersStorage_UpdateUser_Call struct {
 	*mock.Call
 }
 
 // UpdateUser is a helper method to define mock.On call
-//   - r *http.Request
-//   - user models.User
-func (_e *MockUsersStorage_Expecter) UpdateUser(r interface{}, user interface{}) *MockUsersStorage_UpdateUser_Call {
-	return &MockUsersStorage_UpdateUser_Call{Call: _e.mock.On("UpdateUser", r, user)}
+//   - ctx context.Context
+//   - userID int
+//   - upd models.UserUpdate
+func (_e *MockUsersStorage_Expecter) UpdateUser(ctx interface{}, userID interface{}, upd interface{}) *MockUsersStorage_UpdateUser_Call {
+	return &MockUsersStorage_UpdateUser_Call{Call: _e.mock.On("UpdateUser", ctx, userID, upd)}
 }
 
-func (_c *MockUsersStorage_UpdateUser_Call) Run(run func(r *http.Request, user models.User)) *MockUsersStorage_UpdateUser_Call {
+func (_c *MockUsersStorage_UpdateUser_Call) Run(run func(ctx context.Context, userID int, upd models.UserUpdate)) *MockUsersStorage_UpdateUser_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*http.Request), args[1].(models.User))
+		run(args[0].(context.Context), args[1].(int), args[2].(models.UserUpdate))
 	})
 	return _c
 }
 
-func (_c *MockUsersStorage_UpdateUser_Call) Return(_a0 error) *MockUsersStorage_UpdateUser_Call {
-	_c.Call.Return(_a0)
+func (_c *MockUsersStorage_UpdateUser_Call) Return(_a0 *models.User, _a1 error) *MockUsersStorage_UpdateUser_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockUsersStorage_UpdateUser_Call) RunAndReturn(run func(*http.Request, models.User) error) *MockUsersStorage_UpdateUser_Call {
+func (_c *MockUsersStorage_UpdateUser_Call) RunAndReturn(run func(context.Context, int, models.UserUpdate) (*models.User, error)) *MockUsersStorage_UpdateUser_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// UserExists provides a mock function with given fields: username
-func (_m *MockUsersStorage) UserExists(username string) (bool, error) {
-	ret := _m.Called(username)
+// UserExists provides a mock function with given fields: ctx, username
+func (_m *MockUsersStorage) UserExists(ctx context.Context, username string) (bool, error) {
+	ret := _m.Called(ctx, username)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UserExists")
@@ -665,17 +1279,17 @@ func (_m *MockUsersStorage) UserExists(username string) (bool, error) {
 
 	var r0 bool
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (bool, error)); ok {
-		return rf(username)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, username)
 	}
-	if rf, ok := ret.Get(0).(func(string) bool); ok {
-		r0 = rf(username)
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, username)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(username)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -689,14 +1303,15 @@ type MockUsersStorage_UserExists_Call struct {
 }
 
 // UserExists is a helper method to define mock.On call
+//   - ctx context.Context
 //   - username string
-func (_e *MockUsersStorage_Expecter) UserExists(username interface{}) *MockUsersStorage_UserExists_Call {
-	return &MockUsersStorage_UserExists_Call{Call: _e.mock.On("UserExists", username)}
+func (_e *MockUsersStorage_Expecter) UserExists(ctx interface{}, username interface{}) *MockUsersStorage_UserExists_Call {
+	return &MockUsersStorage_UserExists_Call{Call: _e.mock.On("UserExists", ctx, username)}
 }
 
-func (_c *MockUsersStorage_UserExists_Call) Run(run func(username string)) *MockUsersStorage_UserExists_Call {
+func (_c *MockUsersStorage_UserExists_Call) Run(run func(ctx context.Context, username string)) *MockUsersStorage_UserExists_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -706,7 +1321,7 @@ func (_c *MockUsersStorage_UserExists_Call) Return(_a0 bool, _a1 error) *MockUse
 	return _c
 }
 
-func (_c *MockUsersStorage_UserExists_Call) RunAndReturn(run func(string) (bool, error)) *MockUsersStorage_UserExists_Call {
+func (_c *MockUsersStorage_UserExists_Call) RunAndReturn(run func(context.Context, string) (bool, error)) *MockUsersStorage_UserExists_Call {
 	_c.Call.Return(run)
 	return _c
 }
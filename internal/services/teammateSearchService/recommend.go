@@ -0,0 +1,201 @@
+package teammateSearchService
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+// RecommendWeights относительные веса составляющих оценки совместимости
+type RecommendWeights struct {
+	Game       float64
+	Genre      float64
+	Language   float64
+	App        float64
+	Age        float64
+	Popularity float64
+}
+
+func (w RecommendWeights) total() float64 {
+	return w.Game + w.Genre + w.Language + w.App + w.Age + w.Popularity
+}
+
+// RecommendOptions настройки рекомендаций тиммейтов
+type RecommendOptions struct {
+	Weights RecommendWeights
+	// AgeTolerance разница в возрасте (лет), при которой вклад возраста падает до нуля
+	AgeTolerance int
+	// Candidates сколько кандидатов из хранилища оценивается за один запрос
+	Candidates int
+	// Limit размер выдачи по умолчанию
+	Limit int
+}
+
+// popularReasonThreshold доля от самого популярного кандидата, с которой популярность
+// попадает в причины рекомендации
+const popularReasonThreshold = 0.5
+
+// Recommend подбирает тиммейтов, наиболее совместимых с профилем viewer: общие игры, жанры,
+// языки и приложения, близость возраста и популярность с весами из настроек.
+// limit <= 0 означает размер выдачи по умолчанию, больше Candidates — урезается до Candidates
+func (s *Service) Recommend(ctx context.Context, viewer *models.User, limit int) ([]models.Recommendation, error) {
+	switch {
+	case limit <= 0:
+		limit = s.recommend.Limit
+	case limit > s.recommend.Candidates:
+		limit = s.recommend.Candidates
+	}
+	candidates, err := s.storage.RecommendationCandidates(ctx, viewer.ID, s.recommend.Candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	var maxPopularity float64
+	for _, c := range candidates {
+		maxPopularity = math.Max(maxPopularity, c.Popularity)
+	}
+
+	type scored struct {
+		models.Recommendation
+		score float64
+	}
+	var found []scored
+	for _, c := range candidates {
+		score, reasons, matched := s.score(viewer, &c, maxPopularity)
+		if !matched {
+			continue
+		}
+		found = append(found, scored{
+			Recommendation: models.Recommendation{
+				User:    toListShow(&c),
+				Score:   int(math.Round(score * 100)),
+				Reasons: reasons,
+			},
+			score: score,
+		})
+	}
+	// Сортировка устойчивая: при равной оценке сохраняется порядок хранилища (по популярности)
+	slices.SortStableFunc(found, func(a, b scored) int {
+		switch {
+		case a.score > b.score:
+			return -1
+		case a.score < b.score:
+			return 1
+		}
+		return 0
+	})
+	if len(found) > limit {
+		found = found[:limit]
+	}
+
+	recommendations := make([]models.Recommendation, 0, len(found))
+	for _, f := range found {
+		recommendations = append(recommendations, f.Recommendation)
+	}
+	return recommendations, nil
+}
+
+// score возвращает совместимость кандидата от 0 до 1 и причины, по которым он подходит.
+// matched ложно, если с кандидатом нет ничего общего, кроме его популярности.
+// Составляющие с нулевым весом не учитываются и не попадают в причины
+func (s *Service) score(viewer, c *models.User, maxPopularity float64) (score float64, reasons []string, matched bool) {
+	w := s.recommend.Weights
+	var sum float64
+
+	prefs := []struct {
+		weight      float64
+		mine, their []int
+		names       []string
+		label       string
+	}{
+		{w.Game, viewer.GameIDs, c.GameIDs, viewer.Games, "Общие игры"},
+		{w.Genre, viewer.GenreIDs, c.GenreIDs, viewer.Genres, "Общие жанры"},
+		{w.Language, viewer.LanguageIDs, c.LanguageIDs, viewer.Languages, "Общие языки"},
+		{w.App, viewer.AppIDs, c.AppIDs, viewer.Apps, "Общие приложения"},
+	}
+	for _, p := range prefs {
+		if p.weight <= 0 {
+			continue
+		}
+		shared := sharedNames(p.mine, p.their, p.names)
+		if len(shared) == 0 {
+			continue
+		}
+		sum += p.weight * float64(len(shared)) / float64(len(p.mine))
+		reasons = append(reasons, p.label+": "+strings.Join(shared, ", "))
+	}
+
+	if w.Age > 0 && viewer.Age > 0 && c.Age > 0 && s.recommend.AgeTolerance > 0 {
+		diff := viewer.Age - c.Age
+		if diff < 0 {
+			diff = -diff
+		}
+		if closeness := 1 - float64(diff)/float64(s.recommend.AgeTolerance); closeness > 0 {
+			sum += w.Age * closeness
+			if diff == 0 {
+				reasons = append(reasons, "Ровесник")
+			} else {
+				reasons = append(reasons, fmt.Sprintf("Разница в возрасте %d %s", diff, yearsWord(diff)))
+			}
+		}
+	}
+
+	matched = len(reasons) > 0
+
+	if w.Popularity > 0 && maxPopularity > 0 {
+		popularity := c.Popularity / maxPopularity
+		sum += w.Popularity * popularity
+		if popularity >= popularReasonThreshold {
+			reasons = append(reasons, "Популярный игрок")
+		}
+	}
+
+	total := w.total()
+	if total <= 0 {
+		return 0, nil, false
+	}
+	return sum / total, reasons, matched
+}
+
+// sharedNames возвращает названия значений смотрящего, которые есть и у кандидата.
+// names идут в том же порядке, что и mine
+func sharedNames(mine, their []int, names []string) []string {
+	var shared []string
+	for i, id := range mine {
+		if slices.Contains(their, id) && i < len(names) {
+			shared = append(shared, names[i])
+		}
+	}
+	return shared
+}
+
+// toListShow приводит пользователя к виду строки выдачи
+func toListShow(u *models.User) models.UserListShow {
+	return models.UserListShow{
+		Username:      u.Username,
+		Age:           u.Age,
+		Description:   u.Description,
+		MostLikeGame:  u.MostLikeGame,
+		MostLikeGenre: u.MostLikeGenre,
+		App:           u.App,
+		Language:      u.Language,
+		Popularity:    u.Popularity,
+	}
+}
+
+// yearsWord согласует слово «год» с числом
+func yearsWord(n int) string {
+	switch {
+	case n%100 >= 11 && n%100 <= 14:
+		return "лет"
+	case n%10 == 1:
+		return "год"
+	case n%10 >= 2 && n%10 <= 4:
+		return "года"
+	}
+	return "лет"
+}
@@ -2,29 +2,44 @@ package teammateSearchService
 
 import (
 	"context"
-	"database/sql"
-	"log"
-	"net/http"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/rs/zerolog"
+
+	"github.com/DmitriySama/teammate_search/internal/apperr"
 	"github.com/DmitriySama/teammate_search/internal/models"
 	"github.com/DmitriySama/teammate_search/internal/storage/pgstorage"
 )
 
 
 type UsersStorage interface {
-	Register(username, password, description string, age int) (*pgstorage.AuthResult, error)
-	UserExists(username string) (bool, error)
-	Login(username, password string) (*pgstorage.AuthResult, error)
-	UpdateUser(r *http.Request, user models.User) (error)
-	FindUser(username, password string) (int, error)
+	Register(ctx context.Context, username, password, description string, age int) (*pgstorage.AuthResult, error)
+	UserExists(ctx context.Context, username string) (bool, error)
+	Login(ctx context.Context, username, password string) (*pgstorage.AuthResult, error)
+	UpdateUser(ctx context.Context, userID int, upd models.UserUpdate) (*models.User, error)
+	FindUser(ctx context.Context, username, password string) (int, error)
 	
 	GetLanguages(ctx context.Context) ([]models.Language, error)
 	GetGenres(ctx context.Context) ([]models.Genres, error)
 	GetGames(ctx context.Context) ([]models.Games, error)
-	GetUserByID(userID int) (*models.User, error)
+	GetUserByID(ctx context.Context, userID int) (*models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	SearchUsernames(ctx context.Context, query string, limit int) ([]string, error)
 	GetUserCount() (int, error)
-	GetUsers(r *http.Request) (*sql.Rows, error)
+	SearchUsers(ctx context.Context, req models.SearchRequest) (*models.SearchPage, error)
+	RecommendationCandidates(ctx context.Context, userID, limit int) ([]models.User, error)
+	SendTeammateRequest(ctx context.Context, from, to models.TeammateRef) error
+	AcceptTeammateRequest(ctx context.Context, user, from models.TeammateRef) error
+	DeclineTeammateRequest(ctx context.Context, user, from models.TeammateRef) error
+	CancelTeammateRequest(ctx context.Context, user, to models.TeammateRef) error
+	RemoveTeammate(ctx context.Context, user, other models.TeammateRef) error
+	TeammateStatus(ctx context.Context, userID, otherID int) (string, error)
+	ListTeammates(ctx context.Context, userID, limit int) (*models.TeammateLists, error)
 	GetApps(ctx context.Context) ([]models.Apps, error)
+	GetTrending(ctx context.Context, granularity string, since time.Time, limit int) (*models.Trending, error)
 }
 
 type UsersCache interface {
@@ -43,20 +58,26 @@ type UsersCache interface {
 	SetApps(ctx context.Context, apps []models.Apps) error
 }
 
+var (
+	ErrUserExists         = apperr.Conflict("такой ник существует")
+	ErrInvalidCredentials = apperr.Unauthorized("неверное имя пользователя или пароль")
+)
+
 type Service struct {
-	storage UsersStorage
-	cache   UsersCache
+	storage   UsersStorage
+	cache     UsersCache
+	recommend RecommendOptions
 }
 
-func New(storage UsersStorage, cache UsersCache) *Service {
-	return &Service{storage: storage, cache: cache}
+func New(storage UsersStorage, cache UsersCache, recommend RecommendOptions) *Service {
+	return &Service{storage: storage, cache: cache, recommend: recommend}
 }
 
 
 func (s *Service) GetLanguages(ctx context.Context) ([]models.Language, error) {
 	cachedLanguages, ok := s.cache.GetLanguages(ctx)
 	if ok {
-		log.Println("Набор языков взят из REDIS")
+		zerolog.Ctx(ctx).Debug().Msg("Набор языков взят из REDIS")
 		return cachedLanguages, nil
 	}
 
@@ -67,9 +88,9 @@ func (s *Service) GetLanguages(ctx context.Context) ([]models.Language, error) {
 
 	err = s.cache.SetLanguages(ctx, languages)
 	if err == nil {
-		log.Println("Набор языков установлен в REDIS")
+		zerolog.Ctx(ctx).Debug().Msg("Набор языков установлен в REDIS")
 	} else {
-		log.Println("Ошибка при установке языков в REDIS:", err)
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("Ошибка при установке языков в REDIS")
 	}
 	return languages, nil
 }
@@ -77,7 +98,7 @@ func (s *Service) GetLanguages(ctx context.Context) ([]models.Language, error) {
 func (s *Service) GetGenres(ctx context.Context) ([]models.Genres, error) {
 	cachedGenres, ok := s.cache.GetGenres(ctx)
 	if ok {
-		log.Println("Набор жанров взят из REDIS")
+		zerolog.Ctx(ctx).Debug().Msg("Набор жанров взят из REDIS")
 		return cachedGenres, nil
 	}
 
@@ -88,9 +109,9 @@ func (s *Service) GetGenres(ctx context.Context) ([]models.Genres, error) {
 
 	err = s.cache.SetGenres(ctx, genres)
 	if err == nil {
-		log.Println("Жанры добавлены в REDIS")
+		zerolog.Ctx(ctx).Debug().Msg("Жанры добавлены в REDIS")
 	} else {
-		log.Println("Ошибка при добавлении жанров в REDIS:", err)
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("Ошибка при добавлении жанров в REDIS")
 	}
 	return genres, nil
 
@@ -99,7 +120,7 @@ func (s *Service) GetGenres(ctx context.Context) ([]models.Genres, error) {
 func (s *Service) GetGames(ctx context.Context) ([]models.Games, error) {
 	cachedGames, ok := s.cache.GetGames(ctx)
 	if ok {
-		log.Println("Набор игр взят из REDIS")
+		zerolog.Ctx(ctx).Debug().Msg("Набор игр взят из REDIS")
 		return cachedGames, nil
 	}
 
@@ -110,9 +131,9 @@ func (s *Service) GetGames(ctx context.Context) ([]models.Games, error) {
 
 	err = s.cache.SetGames(ctx, games)
 	if err == nil {
-		log.Println("Набор игр добавлен в REDIS")
+		zerolog.Ctx(ctx).Debug().Msg("Набор игр добавлен в REDIS")
 	} else {
-		log.Println("Ошибка при добавлении набора игр в REDIS:", err)
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("Ошибка при добавлении набора игр в REDIS")
 	}
 	return games, nil
 
@@ -121,7 +142,7 @@ func (s *Service) GetGames(ctx context.Context) ([]models.Games, error) {
 func (s *Service) GetApps(ctx context.Context) ([]models.Apps, error) {
 	cachedApps, ok := s.cache.GetApps(ctx)
 	if ok {
-		log.Println("Набор приложений взят из REDIS")
+		zerolog.Ctx(ctx).Debug().Msg("Набор приложений взят из REDIS")
 		return cachedApps, nil
 	}
 
@@ -132,10 +153,117 @@ func (s *Service) GetApps(ctx context.Context) ([]models.Apps, error) {
 
 	err = s.cache.SetApps(ctx, apps)
 	if err == nil {
-		log.Println("Набор приложений добавлен в REDIS")
+		zerolog.Ctx(ctx).Debug().Msg("Набор приложений добавлен в REDIS")
 	} else {
-		log.Println("Ошибка при добавлении приложений в REDIS:", err)
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("Ошибка при добавлении приложений в REDIS")
 	}
 	return apps, nil
 
-}
\ No newline at end of file
+}
+
+// SearchUsers ищет тиммейтов по фильтру и возвращает одну страницу выдачи.
+// С текстовым запросом по умолчанию сортирует по релевантности, без него
+// сортировка по релевантности заменяется на «сначала новые»
+func (s *Service) SearchUsers(ctx context.Context, req models.SearchRequest) (*models.SearchPage, error) {
+	req.Filter.Query = strings.TrimSpace(req.Filter.Query)
+	if utf8.RuneCountInString(req.Filter.Query) > SearchQueryMaxLen {
+		return nil, apperr.Invalid(apperr.FieldErrors{
+			"q": fmt.Sprintf("запрос не должен превышать %d символов", SearchQueryMaxLen),
+		})
+	}
+	switch {
+	case req.Sort == "" && req.Filter.Query != "":
+		req.Sort = models.SortRelevance
+	case req.Sort == "", req.Sort == models.SortRelevance && req.Filter.Query == "":
+		req.Sort = models.SortNewest
+	}
+	if req.Limit <= 0 {
+		req.Limit = models.DefaultSearchLimit
+	}
+	if req.Limit > models.MaxSearchLimit {
+		req.Limit = models.MaxSearchLimit
+	}
+	return s.storage.SearchUsers(ctx, req)
+}
+
+// Register регистрирует пользователя. Некорректные поля возвращаются ошибкой валидации
+// с сообщениями по полям, занятый ник — ErrUserExists
+func (s *Service) Register(ctx context.Context, username, password, description string, age int) (*models.User, error) {
+	username, description = strings.TrimSpace(username), strings.TrimSpace(description)
+	if fields := ValidateRegistration(username, password, description, age); len(fields) > 0 {
+		return nil, apperr.Invalid(fields)
+	}
+
+	// Занятость ника проверяет хранилище, в том числе при одновременной регистрации
+	result, err := s.storage.Register(ctx, username, password, description, age)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Success {
+		return nil, ErrUserExists
+	}
+	return result.User, nil
+}
+
+// Login проверяет учётные данные, при несовпадении возвращает ErrInvalidCredentials
+func (s *Service) Login(ctx context.Context, username, password string) (*models.User, error) {
+	username = strings.TrimSpace(username)
+	if fields := ValidateLogin(username, password); len(fields) > 0 {
+		return nil, apperr.Invalid(fields)
+	}
+
+	result, err := s.storage.Login(ctx, username, password)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Success {
+		return nil, ErrInvalidCredentials
+	}
+	return result.User, nil
+}
+
+// GetUser возвращает пользователя по ID
+func (s *Service) GetUser(ctx context.Context, userID int) (*models.User, error) {
+	return s.storage.GetUserByID(ctx, userID)
+}
+
+// GetUserByUsername возвращает пользователя по нику
+func (s *Service) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	return s.storage.GetUserByUsername(ctx, strings.TrimSpace(username))
+}
+
+// SuggestUsernames подсказывает ники по началу или с опечатками. Для запроса короче
+// UsernameSuggestMinLen символов подсказок нет
+func (s *Service) SuggestUsernames(ctx context.Context, query string, limit int) ([]string, error) {
+	query = strings.TrimSpace(query)
+	if utf8.RuneCountInString(query) < models.UsernameSuggestMinLen {
+		return []string{}, nil
+	}
+	if limit <= 0 {
+		limit = models.DefaultUsernameSuggest
+	}
+	if limit > models.MaxUsernameSuggest {
+		limit = models.MaxUsernameSuggest
+	}
+	return s.storage.SearchUsernames(ctx, query, limit)
+}
+
+// UpdateProfile проверяет и обновляет профиль, возвращает актуальные данные пользователя
+func (s *Service) UpdateProfile(ctx context.Context, userID int, upd models.UserUpdate) (*models.User, error) {
+	upd.Description = strings.TrimSpace(upd.Description)
+	normalizePreferences(&upd)
+	fields, err := s.validateProfile(ctx, upd)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 {
+		return nil, apperr.Invalid(fields)
+	}
+
+	return s.storage.UpdateUser(ctx, userID, upd)
+}
+
+// GetTrending возвращает самые популярные в поиске значения фильтров за окно window до текущего момента
+func (s *Service) GetTrending(ctx context.Context, granularity string, window time.Duration, limit int) (*models.Trending, error) {
+	return s.storage.GetTrending(ctx, granularity, time.Now().Add(-window), limit)
+}
@@ -2,13 +2,14 @@ package teammateSearchService
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"errors"
-	"net/http"
 	"time"
 
 	"github.com/stretchr/testify/suite"
 
+	"github.com/DmitriySama/teammate_search/internal/apperr"
 	"github.com/DmitriySama/teammate_search/internal/services/teammateSearchService/mocks"
 	"github.com/DmitriySama/teammate_search/internal/storage/pgstorage"
 	"github.com/DmitriySama/teammate_search/internal/models"
@@ -20,14 +21,20 @@ type TeammateSearchServiceSuite struct {
 	cache   *mocks.MockUsersCache
 	storage *mocks.MockUsersStorage
 	svc     *Service
-	req         *http.Request
 }
 
 func (s *TeammateSearchServiceSuite) SetupTest() {
 	s.ctx = context.Background()
 	s.cache = mocks.NewMockUsersCache(s.T())
 	s.storage = mocks.NewMockUsersStorage(s.T())
-	s.svc = New(s.storage, s.cache)
+	s.svc = New(s.storage, s.cache, testRecommendOptions)
+}
+
+var testRecommendOptions = RecommendOptions{
+	Weights:      RecommendWeights{Game: 3, Genre: 2, Language: 2, App: 1, Age: 1, Popularity: 1},
+	AgeTolerance: 10,
+	Candidates:   100,
+	Limit:        2,
 }
 
 
@@ -53,10 +60,10 @@ func (s *TeammateSearchServiceSuite) TestRegister_Success() {
 		App: "",
 		CreatedAt: time.Now(),
 	}, Success: true}
-    s.storage.On("Register", username, password, description, age).
+    s.storage.On("Register", s.ctx, username, password, description, age).
         Return(expectedResult, nil)
     
-    result, err := s.svc.storage.Register(username, password, description, age)
+    result, err := s.svc.storage.Register(s.ctx, username, password, description, age)
     
     s.NoError(err)
     s.Equal(expectedResult, result)
@@ -65,19 +72,114 @@ func (s *TeammateSearchServiceSuite) TestRegister_Success() {
 
 func (s *TeammateSearchServiceSuite) TestRegister_UserExists() {
     username := "QQQ"
-    s.storage.On("UserExists", username).Return(true, nil)
+    s.storage.On("Register", s.ctx, username, "secret123", "desc", 20).
+        Return(&pgstorage.AuthResult{Success: false}, nil)
     
-    _, err := s.svc.storage.Register(username, "pass", "desc", 20)
+    _, err := s.svc.Register(s.ctx, username, "secret123", "desc", 20)
     
     s.Error(err)
     s.Contains(err.Error(), "такой ник существует")
 }
 
+func (s *TeammateSearchServiceSuite) TestServiceRegister_Success() {
+    expected := &models.User{ID: 8, Username: "newbie"}
+    s.storage.On("Register", s.ctx, "newbie", "secret123", "desc", 20).
+        Return(&pgstorage.AuthResult{User: expected, Success: true}, nil)
+    
+    user, err := s.svc.Register(s.ctx, "newbie", "secret123", "desc", 20)
+    
+    s.NoError(err)
+    s.Equal(expected, user)
+}
+
+func (s *TeammateSearchServiceSuite) TestServiceLogin_InvalidCredentials() {
+    s.storage.On("Login", s.ctx, "user", "wrong").Return(&pgstorage.AuthResult{Success: false}, nil)
+    
+    _, err := s.svc.Login(s.ctx, "user", "wrong")
+    
+    s.ErrorIs(err, ErrInvalidCredentials)
+}
+
+func (s *TeammateSearchServiceSuite) TestServiceUpdateProfile_ReturnsFreshUser() {
+    upd := models.UserUpdate{Age: 30, Description: "evenings", GameID: 2}
+    expected := &models.User{ID: 3, Age: 30, Description: "evenings", MostLikeGame: "DOTA2"}
+    stored := upd
+    stored.GameIDs = []int{2}
+    s.cache.On("GetGames", s.ctx).Return([]models.Games{{ID: 2, Game: "DOTA2"}}, true)
+    s.storage.On("UpdateUser", s.ctx, 3, stored).Return(expected, nil)
+    
+    user, err := s.svc.UpdateProfile(s.ctx, 3, upd)
+    
+    s.NoError(err)
+    s.Equal(expected, user)
+}
+
+func (s *TeammateSearchServiceSuite) TestServiceRegister_InvalidFields() {
+    _, err := s.svc.Register(s.ctx, "a b", "short", "desc", 7)
+    
+    s.Equal(apperr.KindValidation, apperr.KindOf(err))
+    fields := apperr.FieldsOf(err)
+    s.Contains(fields, "username")
+    s.Contains(fields, "password")
+    s.Contains(fields, "age")
+    s.NotContains(fields, "description")
+}
+
+func (s *TeammateSearchServiceSuite) TestServiceLogin_EmptyFields() {
+    _, err := s.svc.Login(s.ctx, "  ", "")
+    
+    s.Equal(apperr.FieldErrors{"username": "введите имя пользователя", "password": "введите пароль"}, apperr.FieldsOf(err))
+}
+
+func (s *TeammateSearchServiceSuite) TestServiceUpdateProfile_TrimsDescription() {
+    upd := models.UserUpdate{Age: 30, Description: "evenings"}
+    s.storage.On("UpdateUser", s.ctx, 3, upd).Return(&models.User{ID: 3}, nil)
+    
+    _, err := s.svc.UpdateProfile(s.ctx, 3, models.UserUpdate{Age: 30, Description: "  evenings\n"})
+    
+    s.NoError(err)
+}
+
+func (s *TeammateSearchServiceSuite) TestServiceUpdateProfile_OmittedPrivacyKept() {
+    var upd models.UserUpdate
+    s.Require().NoError(json.Unmarshal([]byte(`{"age": 30, "hide_age": true}`), &upd))
+    hideAge := true
+    s.storage.On("UpdateUser", s.ctx, 3, models.UserUpdate{Age: 30, PrivacyUpdate: models.PrivacyUpdate{HideAge: &hideAge}}).
+        Return(&models.User{ID: 3}, nil)
+    
+    _, err := s.svc.UpdateProfile(s.ctx, 3, upd)
+    
+    s.NoError(err)
+    s.Nil(upd.HideProfile)
+    s.Nil(upd.HidePopularity)
+}
+
+func (s *TeammateSearchServiceSuite) TestServiceUpdateProfile_UnknownDictionaryID() {
+    upd := models.UserUpdate{Age: 30, GameID: 99}
+    s.cache.On("GetGames", s.ctx).Return([]models.Games{{ID: 2, Game: "DOTA2"}}, true)
+    
+    _, err := s.svc.UpdateProfile(s.ctx, 3, upd)
+    
+    s.Equal(apperr.FieldErrors{"game": "выберите игры из списка"}, apperr.FieldsOf(err))
+}
+
+func (s *TeammateSearchServiceSuite) TestServiceUpdateProfile_PreferencesSetPrimary() {
+    upd := models.UserUpdate{Age: 30, GameID: 9, Preferences: models.Preferences{LanguageIDs: []int{2, 0, 1, 2}, GameIDs: []int{5}}}
+    stored := models.UserUpdate{Age: 30, GameID: 5, LanguageID: 2, Preferences: models.Preferences{LanguageIDs: []int{2, 1}, GameIDs: []int{5}}}
+    s.cache.On("GetGames", s.ctx).Return([]models.Games{{ID: 5, Game: "CS2"}}, true)
+    s.cache.On("GetLanguages", s.ctx).Return([]models.Language{{ID: 1, Lang: "English"}, {ID: 2, Lang: "Russian"}}, true)
+    s.storage.On("UpdateUser", s.ctx, 3, stored).Return(&models.User{ID: 3}, nil)
+    
+    _, err := s.svc.UpdateProfile(s.ctx, 3, upd)
+    
+    s.NoError(err)
+}
+
 func (s *TeammateSearchServiceSuite) TestUserExists_NotFound() {
     username := "newuser"
-    s.storage.On("UserExists", username).Return(false, nil)
+    s.storage.On("UserExists", s.ctx, username).Return(false, nil)
     
-    exists, err := s.svc.storage.UserExists(username)
+    exists, err := s.svc.storage.UserExists(s.ctx, username)
     
     s.NoError(err)
     s.False(exists)
@@ -87,9 +189,9 @@ func (s *TeammateSearchServiceSuite) TestLogin_Success() {
     username, password := "user", "pass"
     expected := &pgstorage.AuthResult{User: &models.User{}, Success: true}
     
-    s.storage.On("Login", username, password).Return(expected, nil)
+    s.storage.On("Login", s.ctx, username, password).Return(expected, nil)
     
-    result, err := s.svc.storage.Login(username, password)
+    result, err := s.svc.storage.Login(s.ctx, username, password)
     
     s.NoError(err)
     s.Equal(expected, result)
@@ -98,9 +200,9 @@ func (s *TeammateSearchServiceSuite) TestLogin_Success() {
 func (s *TeammateSearchServiceSuite) TestLogin_InvalidCredentials() {
     username, password := "user", "wrongpass"
     
-    s.storage.On("Login", username, password).Return((*pgstorage.AuthResult)(nil), errors.New("invalid username or password"))
+    s.storage.On("Login", s.ctx, username, password).Return((*pgstorage.AuthResult)(nil), errors.New("invalid username or password"))
     
-    result, err := s.svc.storage.Login(username, password)
+    result, err := s.svc.storage.Login(s.ctx, username, password)
     
     s.Error(err)
     s.Nil(result)
@@ -109,18 +211,20 @@ func (s *TeammateSearchServiceSuite) TestLogin_InvalidCredentials() {
 }
 
 func (s *TeammateSearchServiceSuite) TestUpdateUser_Success() {
-    user := models.User{ID: 1, Username: "Updated"}
-    s.storage.On("UpdateUser", s.req, user).Return(nil)
+    upd := models.UserUpdate{Age: 25}
+    expected := &models.User{ID: 1, Username: "Updated", Age: 25}
+    s.storage.On("UpdateUser", s.ctx, 1, upd).Return(expected, nil)
     
-    err := s.svc.storage.UpdateUser(s.req, user)
+    user, err := s.svc.storage.UpdateUser(s.ctx, 1, upd)
     
     s.NoError(err)
+    s.Equal(expected, user)
 }
 
 func (s *TeammateSearchServiceSuite) TestFindUser_Success() {
-    s.storage.On("FindUser", "user", "pass").Return(1, nil)
+    s.storage.On("FindUser", s.ctx, "user", "pass").Return(1, nil)
     
-    id, err := s.svc.storage.FindUser("user", "pass")
+    id, err := s.svc.storage.FindUser(s.ctx, "user", "pass")
     
     s.NoError(err)
     s.Equal(1, id)
@@ -129,7 +233,9 @@ func (s *TeammateSearchServiceSuite) TestFindUser_Success() {
 
 func (s *TeammateSearchServiceSuite) TestGetLanguages_Success() {
     expected := []models.Language{{ID: 1, Lang: "Russian"}, {ID: 2, Lang: "English"}}
+    s.cache.On("GetLanguages", s.ctx).Return(nil, false)
     s.storage.On("GetLanguages", s.ctx).Return(expected, nil)
+    s.cache.On("SetLanguages", s.ctx, expected).Return(nil)
     
     langs, err := s.svc.GetLanguages(s.ctx)
     
@@ -140,7 +246,9 @@ func (s *TeammateSearchServiceSuite) TestGetLanguages_Success() {
 
 func (s *TeammateSearchServiceSuite) TestGetGenres_Success() {
     expected := []models.Genres{{ID: 1, Genre: "Action"}}
+    s.cache.On("GetGenres", s.ctx).Return(nil, false)
     s.storage.On("GetGenres", s.ctx).Return(expected, nil)
+    s.cache.On("SetGenres", s.ctx, expected).Return(nil)
     
     genres, err := s.svc.GetGenres(s.ctx)
     
@@ -150,7 +258,9 @@ func (s *TeammateSearchServiceSuite) TestGetGenres_Success() {
 
 func (s *TeammateSearchServiceSuite) TestGetGames_Success() {
     expected := []models.Games{{ID: 1, Game: "Game1"}}
+    s.cache.On("GetGames", s.ctx).Return(nil, false)
     s.storage.On("GetGames", s.ctx).Return(expected, nil)
+    s.cache.On("SetGames", s.ctx, expected).Return(nil)
     
     games, err := s.svc.GetGames(s.ctx)
     
@@ -160,9 +270,9 @@ func (s *TeammateSearchServiceSuite) TestGetGames_Success() {
 
 func (s *TeammateSearchServiceSuite) TestGetUserByID_Success() {
     expected := &models.User{ID: 1, Username: "John"}
-    s.storage.On("GetUserByID", 1).Return(expected, nil)
+    s.storage.On("GetUserByID", s.ctx, 1).Return(expected, nil)
     
-    user, err := s.svc.storage.GetUserByID(1)
+    user, err := s.svc.storage.GetUserByID(s.ctx, 1)
     
     s.NoError(err)
     s.Equal(expected, user)
@@ -180,7 +290,9 @@ func (s *TeammateSearchServiceSuite) TestGetUserCount_Success() {
 
 func (s *TeammateSearchServiceSuite) TestGetApps_Success() {
     expected := []models.Apps{{ID: 1, App: "App1"}}
+    s.cache.On("GetApps", s.ctx).Return(nil, false)
     s.storage.On("GetApps", s.ctx).Return(expected, nil)
+    s.cache.On("SetApps", s.ctx, expected).Return(nil)
     
     apps, err := s.svc.GetApps(s.ctx)
     
@@ -191,9 +303,182 @@ func (s *TeammateSearchServiceSuite) TestGetApps_Success() {
 // MARK: Error Cases
 
 func (s *TeammateSearchServiceSuite) TestGetLanguages_Error() {
+    s.cache.On("GetLanguages", s.ctx).Return(nil, false)
     s.storage.On("GetLanguages", s.ctx).Return(nil, errors.New("db error"))
     
     _, err := s.svc.GetLanguages(s.ctx)
     
     s.Error(err)
-}
\ No newline at end of file
+}
+
+func (s *TeammateSearchServiceSuite) TestSearchUsers_Success() {
+    req := models.SearchRequest{
+        Filter: models.FilterData{Game: models.FilterValues{"1"}, Genre: models.FilterValues{"-1"}, Language: models.FilterValues{"-1"}, App: models.FilterValues{"-1"}},
+        Sort: models.SortAge,
+        Limit: 10,
+    }
+    expected := &models.SearchPage{Users: []models.UserListShow{{Username: "Aroman", MostLikeGame: "League Of Legends"}}}
+    s.storage.On("SearchUsers", s.ctx, req).Return(expected, nil)
+    
+    page, err := s.svc.SearchUsers(s.ctx, req)
+    
+    s.NoError(err)
+    s.Equal(expected, page)
+}
+
+func (s *TeammateSearchServiceSuite) TestSearchUsers_Defaults() {
+    expectedReq := models.SearchRequest{Sort: models.SortNewest, Limit: models.DefaultSearchLimit}
+    s.storage.On("SearchUsers", s.ctx, expectedReq).Return(&models.SearchPage{}, nil)
+    
+    _, err := s.svc.SearchUsers(s.ctx, models.SearchRequest{})
+    
+    s.NoError(err)
+}
+
+func (s *TeammateSearchServiceSuite) TestSearchUsers_LimitCapped() {
+    expectedReq := models.SearchRequest{Sort: models.SortNewest, Limit: models.MaxSearchLimit}
+    s.storage.On("SearchUsers", s.ctx, expectedReq).Return(&models.SearchPage{}, nil)
+    
+    _, err := s.svc.SearchUsers(s.ctx, models.SearchRequest{Limit: 1000})
+    
+    s.NoError(err)
+}
+
+func (s *TeammateSearchServiceSuite) TestSearchUsers_TextQuerySortsByRelevance() {
+    expectedReq := models.SearchRequest{Filter: models.FilterData{Query: "ranked mic"}, Sort: models.SortRelevance, Limit: models.DefaultSearchLimit}
+    s.storage.On("SearchUsers", s.ctx, expectedReq).Return(&models.SearchPage{}, nil)
+    
+    _, err := s.svc.SearchUsers(s.ctx, models.SearchRequest{Filter: models.FilterData{Query: "  ranked mic "}})
+    
+    s.NoError(err)
+}
+
+func (s *TeammateSearchServiceSuite) TestSearchUsers_RelevanceWithoutQuery() {
+    expectedReq := models.SearchRequest{Sort: models.SortNewest, Limit: models.DefaultSearchLimit}
+    s.storage.On("SearchUsers", s.ctx, expectedReq).Return(&models.SearchPage{}, nil)
+    
+    _, err := s.svc.SearchUsers(s.ctx, models.SearchRequest{Sort: models.SortRelevance})
+    
+    s.NoError(err)
+}
+
+func (s *TeammateSearchServiceSuite) TestSuggestUsernames_ShortQuerySkipsStorage() {
+    usernames, err := s.svc.SuggestUsernames(s.ctx, " d ", 5)
+    
+    s.NoError(err)
+    s.Empty(usernames)
+}
+
+func (s *TeammateSearchServiceSuite) TestSuggestUsernames_LimitCapped() {
+    s.storage.On("SearchUsernames", s.ctx, "dmi", models.MaxUsernameSuggest).Return([]string{"DmitriySama"}, nil)
+    
+    usernames, err := s.svc.SuggestUsernames(s.ctx, " dmi", 1000)
+    
+    s.NoError(err)
+    s.Equal([]string{"DmitriySama"}, usernames)
+}
+
+func (s *TeammateSearchServiceSuite) TestRecommend_RanksByCompatibility() {
+	viewer := &models.User{ID: 1, Age: 20, Games: []string{"Dota 2", "CS2"}, Languages: []string{"Русский"},
+		Preferences: models.Preferences{GameIDs: []int{1, 2}, LanguageIDs: []int{1}}}
+	candidates := []models.User{
+		{ID: 2, Username: "famous", Age: 40, Popularity: 10},
+		{ID: 3, Username: "partial", Age: 25, Popularity: 5, Preferences: models.Preferences{GameIDs: []int{2}}},
+		{ID: 4, Username: "best", Age: 20, Popularity: 1, Preferences: models.Preferences{GameIDs: []int{1, 2, 3}, LanguageIDs: []int{1}}},
+	}
+	s.storage.On("RecommendationCandidates", s.ctx, 1, 100).Return(candidates, nil)
+
+	recs, err := s.svc.Recommend(s.ctx, viewer, 0)
+
+	s.Require().NoError(err)
+	s.Require().Len(recs, 2)
+	s.Equal("best", recs[0].User.Username)
+	s.Equal([]string{"Общие игры: Dota 2, CS2", "Общие языки: Русский", "Ровесник"}, recs[0].Reasons)
+	s.Equal(61, recs[0].Score) // (игры 3 + язык 2 + возраст 1 + популярность 0.1) / 10
+	s.Equal("partial", recs[1].User.Username)
+	s.Equal([]string{"Общие игры: CS2", "Разница в возрасте 5 лет", "Популярный игрок"}, recs[1].Reasons)
+}
+
+func (s *TeammateSearchServiceSuite) TestRecommend_ZeroWeightIgnored() {
+	s.svc.recommend.Weights = RecommendWeights{Genre: 1}
+	viewer := &models.User{ID: 1, Age: 20, Games: []string{"Dota 2"}, Preferences: models.Preferences{GameIDs: []int{1}}}
+	s.storage.On("RecommendationCandidates", s.ctx, 1, 100).
+		Return([]models.User{{ID: 2, Age: 20, Preferences: models.Preferences{GameIDs: []int{1}}}}, nil)
+
+	recs, err := s.svc.Recommend(s.ctx, viewer, 5)
+
+	s.NoError(err)
+	s.Empty(recs)
+}
+
+func (s *TeammateSearchServiceSuite) TestRecommend_LimitClampedToCandidates() {
+	s.svc.recommend.Candidates = 3
+	viewer := &models.User{ID: 1, Games: []string{"Dota 2"}, Preferences: models.Preferences{GameIDs: []int{1}}}
+	candidates := []models.User{
+		{ID: 2, Username: "a", Preferences: models.Preferences{GameIDs: []int{1}}},
+		{ID: 3, Username: "b", Preferences: models.Preferences{GameIDs: []int{1}}},
+		{ID: 4, Username: "c", Preferences: models.Preferences{GameIDs: []int{1}}},
+	}
+	s.storage.On("RecommendationCandidates", s.ctx, 1, 3).Return(candidates, nil)
+
+	recs, err := s.svc.Recommend(s.ctx, viewer, 50)
+
+	s.NoError(err)
+	s.Len(recs, 3) // не размер по умолчанию (2): выдача ограничена числом кандидатов
+}
+
+func (s *TeammateSearchServiceSuite) TestTeammates_Bounded() {
+	lists := &models.TeammateLists{}
+	s.storage.On("ListTeammates", s.ctx, 7, models.TeammateListLimit).Return(lists, nil)
+
+	got, err := s.svc.Teammates(s.ctx, 7)
+
+	s.NoError(err)
+	s.Same(lists, got)
+}
+
+func (s *TeammateSearchServiceSuite) TestTeammateAction_Request() {
+	viewer := &models.User{ID: 1, Username: "me"}
+	s.storage.On("GetUserByUsername", s.ctx, "Player").Return(&models.User{ID: 2, Username: "Player"}, nil)
+	s.storage.On("SendTeammateRequest", s.ctx, models.TeammateRef{ID: 1, Username: "me"}, models.TeammateRef{ID: 2, Username: "Player"}).
+		Return(nil)
+
+	err := s.svc.TeammateAction(s.ctx, viewer, " Player ", models.TeammateActionRequest)
+
+	s.NoError(err)
+}
+
+func (s *TeammateSearchServiceSuite) TestTeammateAction_Self() {
+	viewer := &models.User{ID: 1, Username: "me"}
+	s.storage.On("GetUserByUsername", s.ctx, "ME").Return(&models.User{ID: 1, Username: "me"}, nil)
+
+	err := s.svc.TeammateAction(s.ctx, viewer, "ME", models.TeammateActionRequest)
+
+	s.ErrorIs(err, ErrSelfTeammate)
+	s.Equal(apperr.KindValidation, apperr.KindOf(err))
+}
+
+func (s *TeammateSearchServiceSuite) TestTeammateAction_UnknownActionSkipsStorage() {
+	err := s.svc.TeammateAction(s.ctx, &models.User{ID: 1}, "player", "block")
+
+	s.ErrorIs(err, ErrUnknownTeammateAction)
+}
+
+func (s *TeammateSearchServiceSuite) TestTeammateAction_DuplicateFromStorage() {
+	s.storage.On("GetUserByUsername", s.ctx, "player").Return(&models.User{ID: 2, Username: "player"}, nil)
+	s.storage.On("SendTeammateRequest", s.ctx, models.TeammateRef{ID: 1}, models.TeammateRef{ID: 2, Username: "player"}).
+		Return(pgstorage.ErrTeammateRequestExists)
+
+	err := s.svc.TeammateAction(s.ctx, &models.User{ID: 1}, "player", models.TeammateActionRequest)
+
+	s.Equal(apperr.KindConflict, apperr.KindOf(err))
+}
+
+func (s *TeammateSearchServiceSuite) TestTeammateStatus_OwnProfileSkipsStorage() {
+	user := &models.User{ID: 1}
+
+	status, err := s.svc.TeammateStatus(s.ctx, user, user)
+
+	s.NoError(err)
+	s.Equal(models.TeammateStatusNone, status)
+}
@@ -0,0 +1,61 @@
+package teammateSearchService
+
+import (
+	"context"
+
+	"github.com/DmitriySama/teammate_search/internal/apperr"
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+var (
+	ErrSelfTeammate          = apperr.Validation("нельзя отправить заявку самому себе")
+	ErrUnknownTeammateAction = apperr.Validation("неизвестное действие с заявкой")
+)
+
+// TeammateAction выполняет действие action из models.TeammateAction* между viewer
+// и игроком username. Дубли заявок и ответ на несуществующую заявку проверяет хранилище
+func (s *Service) TeammateAction(ctx context.Context, viewer *models.User, username, action string) error {
+	switch action {
+	case models.TeammateActionRequest, models.TeammateActionAccept, models.TeammateActionDecline,
+		models.TeammateActionCancel, models.TeammateActionRemove:
+	default:
+		return ErrUnknownTeammateAction
+	}
+
+	other, err := s.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if other.ID == viewer.ID {
+		return ErrSelfTeammate
+	}
+
+	me := models.TeammateRef{ID: viewer.ID, Username: viewer.Username}
+	them := models.TeammateRef{ID: other.ID, Username: other.Username}
+	switch action {
+	case models.TeammateActionRequest:
+		return s.storage.SendTeammateRequest(ctx, me, them)
+	case models.TeammateActionAccept:
+		return s.storage.AcceptTeammateRequest(ctx, me, them)
+	case models.TeammateActionDecline:
+		return s.storage.DeclineTeammateRequest(ctx, me, them)
+	case models.TeammateActionCancel:
+		return s.storage.CancelTeammateRequest(ctx, me, them)
+	default:
+		return s.storage.RemoveTeammate(ctx, me, them)
+	}
+}
+
+// TeammateStatus состояние связи viewer с other, для своего профиля — TeammateStatusNone
+func (s *Service) TeammateStatus(ctx context.Context, viewer, other *models.User) (string, error) {
+	if viewer.ID == other.ID {
+		return models.TeammateStatusNone, nil
+	}
+	return s.storage.TeammateStatus(ctx, viewer.ID, other.ID)
+}
+
+// Teammates возвращает последние заявки и тиммейтов пользователя, до models.TeammateListLimit
+// в каждом списке
+func (s *Service) Teammates(ctx context.Context, userID int) (*models.TeammateLists, error) {
+	return s.storage.ListTeammates(ctx, userID, models.TeammateListLimit)
+}
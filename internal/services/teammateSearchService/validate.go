@@ -0,0 +1,184 @@
+package teammateSearchService
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/DmitriySama/teammate_search/internal/apperr"
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+// Ограничения полей профиля, те же значения подсказываются в формах
+const (
+	UsernameMinLen    = 3
+	UsernameMaxLen    = 20
+	PasswordMinLen    = 8
+	PasswordMaxLen    = 72 // bcrypt учитывает только первые 72 байта
+	AgeMin            = 14
+	AgeMax            = 100
+	DescriptionMaxLen = 500
+	// PreferencesMaxCount наибольшее число любимых значений одного справочника
+	PreferencesMaxCount = 10
+	// SearchQueryMaxLen наибольшая длина текстового запроса поиска
+	SearchQueryMaxLen = 200
+)
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// ValidateRegistration проверяет поля регистрации и возвращает сообщения по полям
+func ValidateRegistration(username, password, description string, age int) apperr.FieldErrors {
+	fields := apperr.FieldErrors{}
+	validateUsername(fields, username)
+	validatePassword(fields, password)
+	validateAge(fields, age)
+	validateDescription(fields, description)
+	return fields
+}
+
+// ValidateLogin проверяет только заполненность: требования к паролю могли измениться
+// после регистрации, и старые пароли должны оставаться рабочими
+func ValidateLogin(username, password string) apperr.FieldErrors {
+	fields := apperr.FieldErrors{}
+	if strings.TrimSpace(username) == "" {
+		fields["username"] = "введите имя пользователя"
+	}
+	if password == "" {
+		fields["password"] = "введите пароль"
+	}
+	return fields
+}
+
+func validateUsername(fields apperr.FieldErrors, username string) {
+	n := utf8.RuneCountInString(username)
+	switch {
+	case n == 0:
+		fields["username"] = "введите имя пользователя"
+	case n < UsernameMinLen || n > UsernameMaxLen:
+		fields["username"] = fmt.Sprintf("имя пользователя должно быть от %d до %d символов", UsernameMinLen, UsernameMaxLen)
+	case !usernamePattern.MatchString(username):
+		fields["username"] = "имя пользователя может содержать только латинские буквы, цифры и символы _ . -"
+	}
+}
+
+func validatePassword(fields apperr.FieldErrors, password string) {
+	if len(password) < PasswordMinLen || len(password) > PasswordMaxLen {
+		fields["password"] = fmt.Sprintf("пароль должен быть от %d до %d символов", PasswordMinLen, PasswordMaxLen)
+		return
+	}
+	var letter, digit bool
+	for _, r := range password {
+		letter = letter || unicode.IsLetter(r)
+		digit = digit || unicode.IsDigit(r)
+	}
+	if !letter || !digit {
+		fields["password"] = "пароль должен содержать буквы и цифры"
+	}
+}
+
+func validateAge(fields apperr.FieldErrors, age int) {
+	if age < AgeMin || age > AgeMax {
+		fields["age"] = fmt.Sprintf("возраст должен быть от %d до %d", AgeMin, AgeMax)
+	}
+}
+
+func validateDescription(fields apperr.FieldErrors, description string) {
+	if utf8.RuneCountInString(description) > DescriptionMaxLen {
+		fields["description"] = fmt.Sprintf("описание не должно превышать %d символов", DescriptionMaxLen)
+	}
+}
+
+// normalizePreferences приводит главные значения и списки предпочтений к одному виду:
+// если списки не заданы, они состоят из главного значения, иначе главным становится
+// первое значение списка. Повторы и нули из списков удаляются
+func normalizePreferences(upd *models.UserUpdate) {
+	pairs := []struct {
+		primary *int
+		list    *[]int
+	}{
+		{&upd.GameID, &upd.GameIDs},
+		{&upd.GenreID, &upd.GenreIDs},
+		{&upd.LanguageID, &upd.LanguageIDs},
+		{&upd.AppID, &upd.AppIDs},
+	}
+	for _, p := range pairs {
+		if len(*p.list) == 0 && *p.primary != 0 {
+			*p.list = []int{*p.primary}
+		}
+		var ids []int
+		for _, id := range *p.list {
+			if id != 0 && !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+		*p.list = ids
+		*p.primary = 0
+		if len(ids) > 0 {
+			*p.primary = ids[0]
+		}
+	}
+}
+
+// validateProfile проверяет новые значения профиля. ID предпочтений сверяются со
+// справочниками (через кеш)
+func (s *Service) validateProfile(ctx context.Context, upd models.UserUpdate) (apperr.FieldErrors, error) {
+	fields := apperr.FieldErrors{}
+	validateAge(fields, upd.Age)
+	validateDescription(fields, upd.Description)
+
+	if len(upd.GameIDs) > 0 {
+		games, err := s.GetGames(ctx)
+		if err != nil {
+			return nil, err
+		}
+		validatePreferences(fields, "game", upd.GameIDs, games, func(g models.Games) int { return g.ID }, "выберите игры из списка")
+	}
+	if len(upd.GenreIDs) > 0 {
+		genres, err := s.GetGenres(ctx)
+		if err != nil {
+			return nil, err
+		}
+		validatePreferences(fields, "genre", upd.GenreIDs, genres, func(g models.Genres) int { return g.ID }, "выберите жанры из списка")
+	}
+	if len(upd.LanguageIDs) > 0 {
+		languages, err := s.GetLanguages(ctx)
+		if err != nil {
+			return nil, err
+		}
+		validatePreferences(fields, "language", upd.LanguageIDs, languages, func(l models.Language) int { return l.ID }, "выберите языки из списка")
+	}
+	if len(upd.AppIDs) > 0 {
+		apps, err := s.GetApps(ctx)
+		if err != nil {
+			return nil, err
+		}
+		validatePreferences(fields, "app", upd.AppIDs, apps, func(a models.Apps) int { return a.ID }, "выберите приложения из списка")
+	}
+	return fields, nil
+}
+
+func validatePreferences[T any](fields apperr.FieldErrors, field string, ids []int, items []T, idOf func(T) int, msg string) {
+	if len(ids) > PreferencesMaxCount {
+		fields[field] = fmt.Sprintf("можно выбрать не больше %d значений", PreferencesMaxCount)
+		return
+	}
+	for _, id := range ids {
+		if !containsID(items, id, idOf) {
+			fields[field] = msg
+			return
+		}
+	}
+}
+
+func containsID[T any](items []T, id int, idOf func(T) int) bool {
+	for _, item := range items {
+		if idOf(item) == id {
+			return true
+		}
+	}
+	return false
+}
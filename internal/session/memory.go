@@ -0,0 +1,54 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore хранит сессии в памяти процесса, используется в тестах
+// и как запасной вариант при недоступном Redis
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	userID    int
+	expiresAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string]memoryEntry)}
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.sessions[id]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(s.sessions, id)
+		return 0, ErrNotFound
+	}
+	return entry.userID, nil
+}
+
+func (s *MemoryStore) Set(_ context.Context, id string, userID int, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions[id] = memoryEntry{userID: userID, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, id)
+	return nil
+}
@@ -0,0 +1,43 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore хранит сессии в Redis с TTL на ключе
+type RedisStore struct {
+	client *redis.Client
+}
+
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) key(id string) string {
+	return fmt.Sprintf("session:%s", id)
+}
+
+func (s *RedisStore) Get(ctx context.Context, id string) (int, error) {
+	value, err := s.client.Get(ctx, s.key(id)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	return strconv.Atoi(value)
+}
+
+func (s *RedisStore) Set(ctx context.Context, id string, userID int, ttl time.Duration) error {
+	return s.client.Set(ctx, s.key(id), strconv.Itoa(userID), ttl).Err()
+}
+
+func (s *RedisStore) Delete(ctx context.Context, id string) error {
+	return s.client.Del(ctx, s.key(id)).Err()
+}
@@ -0,0 +1,109 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CookieName имя cookie, в которой хранится идентификатор сессии
+const CookieName = "ts_session"
+
+// ErrNotFound возвращается, если сессия отсутствует или истекла
+var ErrNotFound = errors.New("сессия не найдена")
+
+// Store хранит соответствие идентификатора сессии и ID пользователя
+type Store interface {
+	Get(ctx context.Context, id string) (int, error)
+	Set(ctx context.Context, id string, userID int, ttl time.Duration) error
+	Delete(ctx context.Context, id string) error
+}
+
+// Manager выдаёт, читает и удаляет сессии посетителей
+type Manager struct {
+	store  Store
+	ttl    time.Duration
+	secure bool
+}
+
+// NewManager создаёт менеджер сессий; secure ограничивает отправку cookie протоколом HTTPS
+func NewManager(store Store, ttl time.Duration, secure bool) *Manager {
+	return &Manager{store: store, ttl: ttl, secure: secure}
+}
+
+// Start создаёт новую сессию для пользователя, выставляет cookie и возвращает ID сессии
+func (m *Manager) Start(ctx context.Context, w http.ResponseWriter, userID int) (string, error) {
+	id, err := newID()
+	if err != nil {
+		return "", err
+	}
+	if err := m.store.Set(ctx, id, userID, m.ttl); err != nil {
+		return "", err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     CookieName,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   int(m.ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   m.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return id, nil
+}
+
+// UserID возвращает ID пользователя, привязанного к сессии запроса
+func (m *Manager) UserID(r *http.Request) (int, error) {
+	id := requestSessionID(r)
+	if id == "" {
+		return 0, ErrNotFound
+	}
+	return m.store.Get(r.Context(), id)
+}
+
+// Destroy удаляет сессию запроса и сбрасывает cookie
+func (m *Manager) Destroy(w http.ResponseWriter, r *http.Request) error {
+	http.SetCookie(w, &http.Cookie{
+		Name:     CookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   m.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	id := requestSessionID(r)
+	if id == "" {
+		return nil
+	}
+	return m.store.Delete(r.Context(), id)
+}
+
+// requestSessionID достаёт ID сессии из заголовка Authorization: Bearer
+// (для API-клиентов) или из cookie (для браузера)
+func requestSessionID(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if token, ok := strings.CutPrefix(auth, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	cookie, err := r.Cookie(CookieName)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+func newID() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,130 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SessionSuite struct {
+	suite.Suite
+	ctx     context.Context
+	store   *MemoryStore
+	manager *Manager
+}
+
+func (s *SessionSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.store = NewMemoryStore()
+	s.manager = NewManager(s.store, time.Hour, true)
+}
+
+func TestSessionSuite(t *testing.T) {
+	suite.Run(t, new(SessionSuite))
+}
+
+func (s *SessionSuite) TestMemoryStore_SetGetDelete() {
+	s.NoError(s.store.Set(s.ctx, "abc", 7, time.Minute))
+
+	userID, err := s.store.Get(s.ctx, "abc")
+	s.NoError(err)
+	s.Equal(7, userID)
+
+	s.NoError(s.store.Delete(s.ctx, "abc"))
+	_, err = s.store.Get(s.ctx, "abc")
+	s.ErrorIs(err, ErrNotFound)
+}
+
+func (s *SessionSuite) TestMemoryStore_Expired() {
+	s.NoError(s.store.Set(s.ctx, "old", 1, -time.Second))
+
+	_, err := s.store.Get(s.ctx, "old")
+	s.ErrorIs(err, ErrNotFound)
+}
+
+func (s *SessionSuite) TestManager_StartAndUserID() {
+	rec := httptest.NewRecorder()
+	_, err := s.manager.Start(s.ctx, rec, 42)
+	s.NoError(err)
+
+	cookies := rec.Result().Cookies()
+	s.Require().Len(cookies, 1)
+	s.Equal(CookieName, cookies[0].Name)
+	s.True(cookies[0].HttpOnly)
+	s.True(cookies[0].Secure)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookies[0])
+	userID, err := s.manager.UserID(req)
+	s.NoError(err)
+	s.Equal(42, userID)
+}
+
+func (s *SessionSuite) TestManager_SessionsAreIndependent() {
+	first, second := httptest.NewRecorder(), httptest.NewRecorder()
+	_, err := s.manager.Start(s.ctx, first, 1)
+	s.NoError(err)
+	_, err = s.manager.Start(s.ctx, second, 2)
+	s.NoError(err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(first.Result().Cookies()[0])
+	userID, err := s.manager.UserID(req)
+	s.NoError(err)
+	s.Equal(1, userID)
+}
+
+func (s *SessionSuite) TestManager_BearerToken() {
+	id, err := s.manager.Start(s.ctx, httptest.NewRecorder(), 9)
+	s.NoError(err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+id)
+	userID, err := s.manager.UserID(req)
+	s.NoError(err)
+	s.Equal(9, userID)
+}
+
+func (s *SessionSuite) TestManager_NoCookie() {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	_, err := s.manager.UserID(req)
+	s.ErrorIs(err, ErrNotFound)
+}
+
+func (s *SessionSuite) TestManager_Destroy() {
+	rec := httptest.NewRecorder()
+	_, err := s.manager.Start(s.ctx, rec, 5)
+	s.NoError(err)
+	cookie := rec.Result().Cookies()[0]
+
+	req := httptest.NewRequest(http.MethodPost, "/logout", nil)
+	req.AddCookie(cookie)
+	s.NoError(s.manager.Destroy(httptest.NewRecorder(), req))
+
+	_, err = s.manager.UserID(req)
+	s.ErrorIs(err, ErrNotFound)
+}
+
+func (s *SessionSuite) TestManager_DestroyClearsSecureCookie() {
+	rec := httptest.NewRecorder()
+
+	s.NoError(s.manager.Destroy(rec, httptest.NewRequest(http.MethodPost, "/logout", nil)))
+
+	cookies := rec.Result().Cookies()
+	s.Require().Len(cookies, 1)
+	s.True(cookies[0].Secure)
+	s.Equal(-1, cookies[0].MaxAge)
+}
+
+func (s *SessionSuite) TestManager_InsecureForLocalDev() {
+	rec := httptest.NewRecorder()
+	_, err := NewManager(s.store, time.Hour, false).Start(s.ctx, rec, 1)
+	s.NoError(err)
+
+	s.False(rec.Result().Cookies()[0].Secure)
+}
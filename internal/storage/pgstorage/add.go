@@ -3,23 +3,25 @@ package pgstorage
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"log"
-	"net/http"
-	"strconv"
-	"strings"
+	"encoding/json"
+	"errors"
 	"time"
 
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+
+	"github.com/DmitriySama/teammate_search/internal/metrics"
 	"github.com/DmitriySama/teammate_search/internal/models"
 )
 
 // Register регистрирует нового пользователя
-func (pg *PGstorage) Register(username, password, description string, age int) (*AuthResult, error) {
+func (pg *PGstorage) Register(ctx context.Context, username, password, description string, age int) (*AuthResult, error) {
+    defer metrics.ObserveDBQuery("Register")()
   
     // Проверка уникальности username
-    exists, err := pg.UserExists(username)
+    exists, err := pg.UserExists(ctx, username)
     if err != nil {
-        log.Printf("Ошибка проверки пользователя: %v", err)
+        zerolog.Ctx(ctx).Error().Err(err).Msg("Ошибка проверки пользователя")
         return nil, err
     } 
     if exists {
@@ -29,10 +31,17 @@ func (pg *PGstorage) Register(username, password, description string, age int) (
         }, nil
     }
     
+    // Пароль в БД сохраняется только в виде хеша
+    hash, err := hashPassword(password)
+    if err != nil {
+        zerolog.Ctx(ctx).Error().Err(err).Msg("Ошибка хеширования пароля")
+        return nil, err
+    }
+
     // Создание пользователя
     user := &models.User{
         Username:     username,
-        Password:     password,
+        Password:     hash,
         Description:     description,
         Age:     age,
         CreatedAt:    time.Now(),
@@ -40,19 +49,26 @@ func (pg *PGstorage) Register(username, password, description string, age int) (
     
     // Сохранение в БД
     var userID int
-    err = pg.DB.QueryRow(`
-        INSERT INTO users (username, password, description, age, created_at)
-        VALUES ($1, $2, $3, $4, $5)
-        RETURNING id`, user.Username, user.Password, user.Description, user.Age, user.CreatedAt).Scan(&userID)
+    err = pg.DB.QueryRowContext(ctx, `
+        INSERT INTO users (username, password, description, age, created_at, password_algo)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id`, user.Username, user.Password, user.Description, user.Age, user.CreatedAt, passwordAlgoBcrypt).Scan(&userID)
     
+    // Параллельная регистрация с тем же ником прошла проверку раньше и упёрлась в уникальный ключ
+    if isUniqueViolation(err) {
+        return &AuthResult{
+            Success: false,
+            Message: "Пользователь с таким именем или email уже существует",
+        }, nil
+    }
     if err != nil {
-        log.Printf("Ошибка сохранения пользователя в БД: %v", err)
+        zerolog.Ctx(ctx).Error().Err(err).Msg("Ошибка сохранения пользователя в БД")
         return nil, err
     }
     
     user.ID = userID
     
-    log.Printf("Пользователь зарегистрирован: ID=%d, Username=%s", user.ID, user.Username)
+    zerolog.Ctx(ctx).Info().Int("user_id", user.ID).Str("username", user.Username).Msg("Пользователь зарегистрирован")
     
     return &AuthResult{
         User:    user,
@@ -62,10 +78,11 @@ func (pg *PGstorage) Register(username, password, description string, age int) (
 }
 
 // UserExists проверяет существование пользователя
-func (pg *PGstorage) UserExists(username string) (bool, error) {
+func (pg *PGstorage) UserExists(ctx context.Context, username string) (bool, error) {
+    defer metrics.ObserveDBQuery("UserExists")()
     
 	var count int
-    err := pg.DB.QueryRow(`
+    err := pg.DB.QueryRowContext(ctx, `
         SELECT COUNT(*) 
         FROM users 
         WHERE username = $1
@@ -75,19 +92,35 @@ func (pg *PGstorage) UserExists(username string) (bool, error) {
 }
 
 
-func (pg *PGstorage) SelectUser(username string) {
-    pg.producer.SendUserPopularityData(context.Background(), username)
-}   
+// uniqueViolation код ошибки PostgreSQL при нарушении уникального ключа
+const uniqueViolation = "23505"
+
+// isUniqueViolation сообщает, что запрос нарушил уникальный ключ
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+// SelectUser ставит в outbox событие просмотра анкеты для подсчёта популярности
+func (pg *PGstorage) SelectUser(ctx context.Context, username string) error {
+    defer metrics.ObserveDBQuery("SelectUser")()
+    return enqueueEvent(ctx, pg.DB, pg.topics.UserPopularity, username, []byte(username))
+}
 
-func (pg *PGstorage) FilterData(fd models.FilterData) error {
-    err := pg.producer.SendFilterData(context.Background(), fd)
-    return err
-}   
+// FilterData ставит в outbox параметры поиска для аналитики
+func (pg *PGstorage) FilterData(ctx context.Context, fd models.FilterData) error {
+    defer metrics.ObserveDBQuery("FilterData")()
+    data, err := json.Marshal(fd)
+    if err != nil {
+        return err
+    }
+    return enqueueEvent(ctx, pg.DB, pg.topics.FilterData, "", data)
+}
 
 
-func (pg *PGstorage) Login(username, password string) (*AuthResult, error) {    
+func (pg *PGstorage) Login(ctx context.Context, username, password string) (*AuthResult, error) {    
     
-    user_id, err := pg.FindUser(username, password)
+    user_id, err := pg.FindUser(ctx, username, password)
     if err != nil {
         if err == sql.ErrNoRows {
             return &AuthResult{
@@ -95,23 +128,23 @@ func (pg *PGstorage) Login(username, password string) (*AuthResult, error) {
                 Message: "Неверное имя пользователя или пароль",
             }, nil
         }
-        log.Printf("Ошибка поиска пользователя: %v", err)
+        zerolog.Ctx(ctx).Error().Err(err).Msg("Ошибка поиска пользователя")
         return nil, err
     }
     
-    user, err := pg.GetUserByID(user_id)
+    user, err := pg.GetUserByID(ctx, user_id)
     if err != nil {
-        if err == sql.ErrNoRows {
+        if errors.Is(err, ErrUserNotFound) {
             return &AuthResult{
                 Success: false,
                 Message: "",
             }, nil
         }
-        log.Printf("Ошибка получения пользователя: %v", err)
+        zerolog.Ctx(ctx).Error().Err(err).Msg("Ошибка получения пользователя")
         return nil, err
     }
 
-    log.Printf("Пользователь вошел: ID=%d, Username=%s", user_id, user.Username)
+    zerolog.Ctx(ctx).Info().Int("user_id", user_id).Str("username", user.Username).Msg("Пользователь вошел")
     
     return &AuthResult{
         User:    user,
@@ -120,77 +153,29 @@ func (pg *PGstorage) Login(username, password string) (*AuthResult, error) {
     }, nil
 }
 
-func (pg *PGstorage) UpdateUser(r *http.Request, user models.User) (error) {
-
-    // Динамически строим запрос
-    var setClpges []string
-    var args []interface{}
-    argIndex := 1
-    
-    age, _ := strconv.Atoi(r.FormValue("age"))
-    if age != user.Age {
-        setClpges = append(setClpges, fmt.Sprintf("age = $%d", argIndex))
-        args = append(args, age)
-        argIndex++
-        log.Println("AGE:", age, user.Age)
-    }
-    desc := strings.TrimSpace(r.FormValue("description"))
-    if desc != user.Description {
-        setClpges = append(setClpges, fmt.Sprintf("description = $%d", argIndex))
-        args = append(args, desc)
-        argIndex++
-        log.Println("description:", r.FormValue("description"), user.Description)
-    }
-    MostLikeGame := strings.SplitN(r.FormValue("game"), " ", 2)[1]
-    if MostLikeGame != user.MostLikeGame {
-        id_game := strings.SplitN(r.FormValue("game"), " ", 2)[0]
-        setClpges = append(setClpges, fmt.Sprintf("most_like_game = $%d", argIndex))
-        args = append(args, id_game)
-        argIndex++
-        log.Println("MostLikeGame:", MostLikeGame, user.MostLikeGame)
-    }
-    MostLikeGenre := strings.SplitN(r.FormValue("genre"), " ", 2)[1]
-    if MostLikeGenre != user.MostLikeGenre {
-        id_genre := strings.SplitN(r.FormValue("genre"), " ", 2)[0]
-        setClpges = append(setClpges, fmt.Sprintf("most_like_genre = $%d", argIndex))
-        args = append(args, id_genre)
-        argIndex++
-        log.Println("MostLikeGenre:", MostLikeGenre, user.MostLikeGenre)
-    }
-    App := strings.SplitN(r.FormValue("app"), " ", 2)[1]
-    if App != user.App {
-        id_app := strings.SplitN(r.FormValue("app"), " ", 2)[0]
-        setClpges = append(setClpges, fmt.Sprintf("speaking_app = $%d", argIndex))
-        args = append(args, id_app)
-        argIndex++
-        log.Println("App:", r.FormValue("app"), user.App)
-    }
-    Language := strings.SplitN(r.FormValue("language"), " ", 2)[1]
-    if Language != user.Language {
-        lang_id := strings.SplitN(r.FormValue("language"), " ", 2)[0]
-        setClpges = append(setClpges, fmt.Sprintf("language = $%d", argIndex))
-        args = append(args, lang_id)
-        argIndex++
-        log.Println("Language:", Language, user.Language)
-    }
-    
-    // Если ничего не изменилось
-    log.Println(len(setClpges))
-    log.Println(setClpges)
-    log.Println(args)
-    if len(setClpges) == 0 {
-        log.Println("Ничего не изменилось")
-        return nil
-    }
-
-    // Добавляем WHERE
-    args = append(args, user.ID)
-    query := fmt.Sprintf(
-        "UPDATE users SET %s WHERE id = $%d",
-        strings.Join(setClpges, ", "),
-        argIndex,
-    )
-
-    _, err := pg.DB.Exec(query, args...)
-    return err
-}
\ No newline at end of file
+// UpdateUser полностью заменяет редактируемые поля и предпочтения профиля и меняет заданные
+// настройки приватности, публикует событие UpdateUserData через outbox и возвращает строку
+// пользователя после изменения. Справочники задаются ID, 0 сбрасывает главное значение
+func (pg *PGstorage) UpdateUser(ctx context.Context, userID int, upd models.UserUpdate) (*models.User, error) {
+    defer metrics.ObserveDBQuery("UpdateUser")()
+    return pg.updateUserWithEvent(ctx, userID, func(tx *sql.Tx) error {
+        _, err := tx.ExecContext(ctx, `
+            UPDATE users
+            SET age = $1,
+                description = $2,
+                most_like_game = NULLIF($3, 0),
+                most_like_genre = NULLIF($4, 0),
+                language = NULLIF($5, 0),
+                speaking_app = NULLIF($6, 0),
+                hide_profile = COALESCE($7, hide_profile),
+                hide_age = COALESCE($8, hide_age),
+                hide_popularity = COALESCE($9, hide_popularity)
+            WHERE id = $10
+        `, upd.Age, upd.Description, upd.GameID, upd.GenreID, upd.LanguageID, upd.AppID,
+            upd.HideProfile, upd.HideAge, upd.HidePopularity, userID)
+        if err != nil {
+            return err
+        }
+        return replacePreferences(ctx, tx, userID, upd.Preferences)
+    })
+}
@@ -0,0 +1,49 @@
+package pgstorage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/DmitriySama/teammate_search/internal/apperr"
+)
+
+// Направления перехода по курсору
+const (
+	cursorNext = "next"
+	cursorPrev = "prev"
+)
+
+// ErrInvalidCursor возвращается для повреждённого или чужого курсора
+var ErrInvalidCursor = apperr.Validation("некорректный курсор страницы")
+
+// searchCursor позиция в выдаче: значение ключа сортировки и ID граничной строки
+type searchCursor struct {
+	Sort string  `json:"s"`
+	Key  float64 `json:"k"`
+	ID   int     `json:"id"`
+	Dir  string  `json:"d"`
+}
+
+func encodeCursor(c searchCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value, sort string) (*searchCursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c searchCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != sort || (c.Dir != cursorNext && c.Dir != cursorPrev) {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
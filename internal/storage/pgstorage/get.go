@@ -2,29 +2,69 @@
 package pgstorage
 
 import (
-	"log"
 	"time"
 	"context"
-	"errors"
-	"net/http"
 	"database/sql"
+	"github.com/rs/zerolog"
+	"github.com/DmitriySama/teammate_search/internal/apperr"
+	"github.com/DmitriySama/teammate_search/internal/metrics"
 	"github.com/DmitriySama/teammate_search/internal/models"
 )
 
 
-func (pg *PGstorage) FindUser(username, password string) (int, error) {
+// ErrUserNotFound возвращается, если пользователя с таким ID или ником нет
+var ErrUserNotFound = apperr.NotFound("пользователь не найден")
+
+// FindUser ищет пользователя по имени и проверяет пароль.
+// При неверном пароле возвращает sql.ErrNoRows, как и при отсутствии пользователя
+func (pg *PGstorage) FindUser(ctx context.Context, username, password string) (int, error) {
+    defer metrics.ObserveDBQuery("FindUser")()
     var id int
-    err := pg.DB.QueryRow(`
-        SELECT id
+    var stored, algo string
+    err := pg.DB.QueryRowContext(ctx, `
+        SELECT id, password, password_algo
         FROM users 
-        WHERE username = $1 and password = $2
-    `, username, password).Scan(&id)
+        WHERE username = $1
+    `, username).Scan(&id, &stored, &algo)
+    if err != nil {
+        return 0, err
+    }
+
+    ok, needsRehash := checkPassword(stored, algo, password)
+    if !ok {
+        return 0, sql.ErrNoRows
+    }
+    if needsRehash {
+        pg.upgradePassword(ctx, id, algo, password)
+    }
     
-    return id, err
+    return id, nil
+}
+
+// upgradePassword перезаписывает пароль пользователя актуальным хешем.
+// Ошибка не мешает входу и только логируется
+func (pg *PGstorage) upgradePassword(ctx context.Context, userID int, oldAlgo, password string) {
+    hash, err := hashPassword(password)
+    if err != nil {
+        zerolog.Ctx(ctx).Error().Err(err).Int("user_id", userID).Msg("Ошибка хеширования пароля пользователя")
+        return
+    }
+
+    _, err = pg.DB.ExecContext(ctx, `
+        UPDATE users
+        SET password = $1, password_algo = $2
+        WHERE id = $3 and password_algo = $4
+    `, hash, passwordAlgoBcrypt, userID, oldAlgo)
+    if err != nil {
+        zerolog.Ctx(ctx).Error().Err(err).Int("user_id", userID).Msg("Ошибка обновления хеша пароля пользователя")
+        return
+    }
+    zerolog.Ctx(ctx).Info().Int("user_id", userID).Str("algo", passwordAlgoBcrypt).Msg("Пароль пользователя перевыпущен")
 }
 
 
 func (pg *PGstorage) GetLanguages(ctx context.Context) ([]models.Language, error) {
+    defer metrics.ObserveDBQuery("GetLanguages")()
     query := `SELECT id_language, language FROM languages`
     
     rows, err := pg.DB.QueryContext(ctx, query)
@@ -46,6 +86,7 @@ func (pg *PGstorage) GetLanguages(ctx context.Context) ([]models.Language, error
 }
 
 func (pg *PGstorage) GetGenres(ctx context.Context) ([]models.Genres, error) {
+    defer metrics.ObserveDBQuery("GetGenres")()
     query := `SELECT id_genre, genre FROM genres`
     
     rows, err := pg.DB.QueryContext(ctx, query)
@@ -67,6 +108,7 @@ func (pg *PGstorage) GetGenres(ctx context.Context) ([]models.Genres, error) {
 }
 
 func (pg *PGstorage) GetGames(ctx context.Context) ([]models.Games, error) {
+    defer metrics.ObserveDBQuery("GetGames")()
     query := `SELECT id_game, game FROM games`
     
     rows, err := pg.DB.QueryContext(ctx, query)
@@ -88,6 +130,7 @@ func (pg *PGstorage) GetGames(ctx context.Context) ([]models.Games, error) {
 }
 
 func (pg *PGstorage) GetApps(ctx context.Context) ([]models.Apps, error) {
+    defer metrics.ObserveDBQuery("GetApps")()
     query := `SELECT id_app, app FROM apps`
     
     rows, err := pg.DB.QueryContext(ctx, query)
@@ -108,12 +151,32 @@ func (pg *PGstorage) GetApps(ctx context.Context) ([]models.Apps, error) {
     return apps, nil
 }
 
-func (pg *PGstorage) GetUserByID(userID int) (*models.User, error) {
+func (pg *PGstorage) GetUserByID(ctx context.Context, userID int) (*models.User, error) {
+    defer metrics.ObserveDBQuery("GetUserByID")()
+    return getUserByID(ctx, pg.DB, userID)
+}
+
+// getUserByID читает пользователя с названиями значений справочников и предпочтениями
+func getUserByID(ctx context.Context, q querier, userID int) (*models.User, error) {
+    return getUser(ctx, q, "u.id = $1", userID)
+}
+
+// GetUserByUsername возвращает пользователя по нику без учёта регистра
+func (pg *PGstorage) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+    defer metrics.ObserveDBQuery("GetUserByUsername")()
+    return getUser(ctx, pg.DB, "lower(u.username) = lower($1)", username)
+}
+
+// getUser читает пользователя с названиями справочников и предпочтениями,
+// where — условие на единственный параметр $1
+func getUser(ctx context.Context, q querier, where string, arg interface{}) (*models.User, error) {
     var username, password, f_game, f_genre, app, description, lang string
     var id, age int 
+    var popularity float64
     var created_at time.Time 
+    var privacy models.Privacy
 
-    err := pg.DB.QueryRow(`
+    err := q.QueryRowContext(ctx, `
         SELECT 
             u.id, 
             u.username, 
@@ -121,6 +184,10 @@ func (pg *PGstorage) GetUserByID(userID int) (*models.User, error) {
             u.age, 
             u.description, 
             u.created_at,
+            u.popularity,
+            u.hide_profile,
+            u.hide_age,
+            u.hide_popularity,
             
             COALESCE(g1.game, '') AS f_game,
             COALESCE(g.genre, '') AS f_genre,
@@ -131,8 +198,7 @@ func (pg *PGstorage) GetUserByID(userID int) (*models.User, error) {
         LEFT JOIN languages l ON u.language = l.id_language
         LEFT JOIN apps a ON u.speaking_app = a.id_app
         LEFT JOIN games g1 ON u.most_like_game = g1.id_game
-        WHERE u.id = $1;
-    `, userID).Scan(&id, &username, &password, &age, &description, &created_at, &f_game, &f_genre, &app, &lang)
+        WHERE `+where, arg).Scan(&id, &username, &password, &age, &description, &created_at, &popularity, &privacy.HideProfile, &privacy.HideAge, &privacy.HidePopularity, &f_game, &f_genre, &app, &lang)
     
     user := &models.User{
         ID:          id,
@@ -144,20 +210,26 @@ func (pg *PGstorage) GetUserByID(userID int) (*models.User, error) {
         MostLikeGame: f_game,
         App: app,
         Language: lang,
+        Popularity: popularity,
         CreatedAt:   created_at,
+        Privacy: privacy,
     }
     
     if err != nil {
         if err == sql.ErrNoRows {
-            return nil, errors.New("пользователь не найден")
+            return nil, ErrUserNotFound
         }
         return nil, err
     }
+    if err := loadPreferences(ctx, q, user); err != nil {
+        return nil, err
+    }
     
     return user, nil
 }
 
 func (pg *PGstorage) GetUserCount() (int, error) {
+    defer metrics.ObserveDBQuery("GetUserCount")()
     var value int
     err := pg.DB.QueryRow(`SELECT count(*) from users`).Scan(&value)
     if err != nil {
@@ -166,52 +238,3 @@ func (pg *PGstorage) GetUserCount() (int, error) {
 
     return value, err
 }
-
-func (pg *PGstorage) GetUsers(r *http.Request) (*sql.Rows, error) {
-    
-    query := `SELECT 
-            u.username, 
-            u.age, 
-            u.description, 
-            
-            COALESCE(g1.game, '') AS f_game,
-            COALESCE(g.genre, '') AS f_genre,
-            COALESCE(a.app, '') AS app,
-            COALESCE(l.language, '') AS lang
-        FROM users u
-        LEFT JOIN genres g ON u.most_like_genre = g.id_genre
-        LEFT JOIN languages l ON u.language = l.id_language
-        LEFT JOIN apps a ON u.speaking_app = a.id_app
-        LEFT JOIN games g1 ON u.most_like_game = g1.id_game
-        WHERE u.age between $1 and $2 `
-        
-    if r.FormValue("genre") != "-1" {
-        query += ` and u.most_like_genre = ` + r.FormValue("genre")
-    }
-    if r.FormValue("game") != "-1" {
-        query += ` and u.most_like_game = ` + r.FormValue("game") 
-    }
-    if r.FormValue("language") != "-1" {
-        query += ` and u.language = ` + r.FormValue("language")
-    }
-
-    var age0, age1 string
-    if r.FormValue("age0") == "" {
-        age0 = "0"
-    } else {
-        age0 = r.FormValue("age0")
-    }
-    if r.FormValue("age1") == "" {
-        age1 = "100"
-    } else {
-        age1 = r.FormValue("age1")
-    }
-
-    rows, err := pg.DB.Query(query, age0, age1)
-    if err != nil {
-        log.Fatal("Ошибка при выполнении запроса: ", err.Error())
-        return nil, err
-    }
-
-    return rows, err
-}
\ No newline at end of file
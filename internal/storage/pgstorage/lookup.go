@@ -0,0 +1,44 @@
+package pgstorage
+
+import (
+	"context"
+	"strings"
+
+	"github.com/DmitriySama/teammate_search/internal/metrics"
+)
+
+// likeEscaper экранирует спецсимволы шаблона LIKE
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// searchUsernamesQuery: сначала ники, начинающиеся с запроса, затем похожие с учётом
+// опечаток (word_similarity, оператор <%), обе ветки используют триграммный индекс.
+// Скрытые профили в подсказки не попадают, как и в поиск
+const searchUsernamesQuery = `
+        SELECT u.username
+        FROM users u
+        WHERE ` + searchVisibleCond + ` AND (lower(u.username) LIKE $2 ESCAPE '\' OR $1 <% lower(u.username))
+        ORDER BY lower(u.username) LIKE $2 ESCAPE '\' DESC,
+            word_similarity($1, lower(u.username)) DESC,
+            u.username
+        LIMIT $3`
+
+// SearchUsernames возвращает до limit ников, похожих на query
+func (pg *PGstorage) SearchUsernames(ctx context.Context, query string, limit int) ([]string, error) {
+	defer metrics.ObserveDBQuery("SearchUsernames")()
+	query = strings.ToLower(query)
+	rows, err := pg.DB.QueryContext(ctx, searchUsernamesQuery, query, likeEscaper.Replace(query)+"%", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usernames []string
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, err
+		}
+		usernames = append(usernames, username)
+	}
+	return usernames, rows.Err()
+}
@@ -0,0 +1,58 @@
+package pgstorage
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type LookupSuite struct {
+	suite.Suite
+	ctx  context.Context
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	pg   *PGstorage
+}
+
+func (s *LookupSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+	s.ctx = context.Background()
+	s.db, s.mock = db, mock
+	s.pg = &PGstorage{DB: db}
+}
+
+func (s *LookupSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+func TestLookupSuite(t *testing.T) {
+	suite.Run(t, new(LookupSuite))
+}
+
+func (s *LookupSuite) TestSearchUsernames_EscapesLike() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("<% lower(u.username)")).
+		WithArgs("dm_1%", `dm\_1\%%`, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"username"}).AddRow("Dm_1%").AddRow("dm_2"))
+
+	usernames, err := s.pg.SearchUsernames(s.ctx, "Dm_1%", 5)
+
+	s.NoError(err)
+	s.Equal([]string{"Dm_1%", "dm_2"}, usernames)
+}
+
+func (s *LookupSuite) TestSearchUsernames_SkipsHiddenProfiles() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`WHERE NOT u.hide_profile AND (lower(u.username) LIKE $2 ESCAPE '\' OR $1 <% lower(u.username))`)).
+		WithArgs("dm", "dm%", 5).
+		WillReturnRows(sqlmock.NewRows([]string{"username"}))
+
+	usernames, err := s.pg.SearchUsernames(s.ctx, "dm", 5)
+
+	s.NoError(err)
+	s.Empty(usernames)
+}
@@ -0,0 +1,298 @@
+package pgstorage
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/rs/zerolog"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationLockID ключ pg_advisory_lock, под которым реплики по очереди применяют миграции
+const migrationLockID = 7_351_902_114
+
+// migrationFileRe имя файла миграции: 001_init_schema.up.sql / 001_init_schema.down.sql
+var migrationFileRe = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration версия схемы: SQL применения и отката
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// loadMigrations читает встроенные в бинарник миграции, отсортированные по версии
+func loadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileRe.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("некорректное имя файла миграции %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		data, err := migrationsFS.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("у версии %d разные имена миграций: %s и %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("у миграции %03d_%s нет файла up", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// MigrateUp применяет все ещё не применённые миграции, каждую в своей транзакции.
+// База, созданная до мигратора скриптом initdb, сначала отмечается применённой
+// до обнаруженной версии (см. legacySchemaVersion). Возвращает число применённых миграций
+func (pg *PGstorage) MigrateUp(ctx context.Context) (int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = pg.withMigrationLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			legacy, err := legacySchemaVersion(ctx, conn)
+			if err != nil {
+				return err
+			}
+			if legacy > 0 {
+				zerolog.Ctx(ctx).Warn().Int("version", legacy).
+					Msg("База создана до встроенного мигратора, миграции до этой версии отмечены применёнными")
+				if err := markApplied(ctx, conn, migrations, legacy); err != nil {
+					return err
+				}
+				for _, m := range migrations {
+					if m.Version <= legacy {
+						applied[m.Version] = true
+					}
+				}
+			}
+		}
+		for _, m := range migrations {
+			if applied[m.Version] {
+				continue
+			}
+			zerolog.Ctx(ctx).Info().Int("version", m.Version).Str("name", m.Name).Msg("Применение миграции")
+			if err := runMigration(ctx, conn, m.Up, `
+                INSERT INTO public.schema_migrations (version, name) VALUES ($1, $2)
+            `, m.Version, m.Name); err != nil {
+				return fmt.Errorf("миграция %03d_%s: %w", m.Version, m.Name, err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// MigrateDown откатывает steps последних применённых миграций. Возвращает число откатов
+func (pg *PGstorage) MigrateDown(ctx context.Context, steps int) (int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = pg.withMigrationLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(migrations) - 1; i >= 0 && count < steps; i-- {
+			m := migrations[i]
+			if !applied[m.Version] {
+				continue
+			}
+			if m.Down == "" {
+				return fmt.Errorf("у миграции %03d_%s нет файла down", m.Version, m.Name)
+			}
+			zerolog.Ctx(ctx).Info().Int("version", m.Version).Str("name", m.Name).Msg("Откат миграции")
+			if err := runMigration(ctx, conn, m.Down, `
+                DELETE FROM public.schema_migrations WHERE version = $1
+            `, m.Version); err != nil {
+				return fmt.Errorf("откат %03d_%s: %w", m.Version, m.Name, err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// MigrateBaseline отмечает миграции до version включительно как применённые, не выполняя их.
+// Нужна для баз, созданных раньше встроенного мигратора
+func (pg *PGstorage) MigrateBaseline(ctx context.Context, version int) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	return pg.withMigrationLock(ctx, func(conn *sql.Conn) error {
+		return markApplied(ctx, conn, migrations, version)
+	})
+}
+
+// markApplied записывает миграции до version включительно в schema_migrations, не выполняя их
+func markApplied(ctx context.Context, conn *sql.Conn, migrations []Migration, version int) error {
+	for _, m := range migrations {
+		if m.Version > version {
+			break
+		}
+		if _, err := conn.ExecContext(ctx, `
+            INSERT INTO public.schema_migrations (version, name) VALUES ($1, $2)
+            ON CONFLICT (version) DO NOTHING
+        `, m.Version, m.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// legacySchemaVersion определяет версию схемы базы, созданной до мигратора: раньше
+// docker-compose выполнял файлы миграций 001–006 через docker-entrypoint-initdb.d без
+// записи в schema_migrations. Каждая колонка запроса — объект, созданный миграцией
+// соответствующей версии; версия — число подряд найденных объектов, 0 — база пустая
+func legacySchemaVersion(ctx context.Context, conn *sql.Conn) (int, error) {
+	markers := make([]bool, 6)
+	err := conn.QueryRowContext(ctx, `
+        SELECT to_regclass('public.users') IS NOT NULL,
+               EXISTS (SELECT 1 FROM information_schema.columns
+                       WHERE table_schema = 'public' AND table_name = 'users' AND column_name = 'password_algo'),
+               EXISTS (SELECT 1 FROM information_schema.columns
+                       WHERE table_schema = 'public' AND table_name = 'users' AND column_name = 'popularity'),
+               EXISTS (SELECT 1 FROM information_schema.columns
+                       WHERE table_schema = 'public' AND table_name = 'users' AND column_name = 'popularity_updated_at'),
+               to_regclass('public.search_trends') IS NOT NULL,
+               to_regclass('public.outbox') IS NOT NULL
+    `).Scan(&markers[0], &markers[1], &markers[2], &markers[3], &markers[4], &markers[5])
+	if err != nil {
+		return 0, err
+	}
+	version := 0
+	for _, found := range markers {
+		if !found {
+			break
+		}
+		version++
+	}
+	return version, nil
+}
+
+// SchemaVersion возвращает последнюю применённую версию схемы, 0 — если миграций не было
+func (pg *PGstorage) SchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	err := pg.withMigrationLock(ctx, func(conn *sql.Conn) error {
+		return conn.QueryRowContext(ctx, `
+            SELECT COALESCE(MAX(version), 0) FROM public.schema_migrations
+        `).Scan(&version)
+	})
+	return version, err
+}
+
+// withMigrationLock выполняет fn на отдельном соединении под advisory lock,
+// предварительно создав таблицу версий. Параллельные реплики ждут, пока первая закончит
+func (pg *PGstorage) withMigrationLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := pg.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return err
+	}
+	defer func() {
+		// Миграции могут менять параметры сессии (SET ...), соединение возвращается в пул чистым
+		if _, err := conn.ExecContext(context.Background(), `RESET ALL`); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("Ошибка сброса параметров сессии")
+		}
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("Ошибка снятия блокировки миграций")
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, `
+        CREATE TABLE IF NOT EXISTS public.schema_migrations (
+            version integer PRIMARY KEY,
+            name text NOT NULL,
+            applied_at timestamp with time zone NOT NULL DEFAULT now()
+        )
+    `); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]bool, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version FROM public.schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]bool{}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// runMigration выполняет SQL миграции и запись в schema_migrations в одной транзакции
+func runMigration(ctx context.Context, conn *sql.Conn, script, record string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
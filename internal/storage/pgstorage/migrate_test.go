@@ -0,0 +1,142 @@
+package pgstorage
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MigrateSuite struct {
+	suite.Suite
+	ctx  context.Context
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	pg   *PGstorage
+}
+
+func (s *MigrateSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+	s.ctx = context.Background()
+	s.db, s.mock = db, mock
+	s.pg = &PGstorage{DB: db}
+}
+
+func (s *MigrateSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+func TestMigrateSuite(t *testing.T) {
+	suite.Run(t, new(MigrateSuite))
+}
+
+func (s *MigrateSuite) TestLoadMigrations_Sequential() {
+	migrations, err := loadMigrations()
+
+	s.Require().NoError(err)
+	s.Require().NotEmpty(migrations)
+	for i, m := range migrations {
+		s.Equal(i+1, m.Version)
+		s.NotEmpty(m.Down, "миграция %03d_%s без down", m.Version, m.Name)
+		// COPY FROM stdin понимает только psql
+		s.NotContains(m.Up, "FROM stdin")
+	}
+}
+
+func (s *MigrateSuite) expectLock() {
+	s.mock.ExpectExec(regexp.QuoteMeta("pg_advisory_lock")).WithArgs(migrationLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS public.schema_migrations")).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func (s *MigrateSuite) expectUnlock() {
+	s.mock.ExpectExec("RESET ALL").WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectExec(regexp.QuoteMeta("pg_advisory_unlock")).WithArgs(migrationLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func (s *MigrateSuite) TestMigrateUp_AppliesPending() {
+	migrations, err := loadMigrations()
+	s.Require().NoError(err)
+	last := migrations[len(migrations)-1]
+
+	applied := sqlmock.NewRows([]string{"version"})
+	for _, m := range migrations[:len(migrations)-1] {
+		applied.AddRow(m.Version)
+	}
+	s.expectLock()
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM public.schema_migrations")).WillReturnRows(applied)
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(strings.TrimSpace(last.Up))).WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO public.schema_migrations")).
+		WithArgs(last.Version, last.Name).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+	s.expectUnlock()
+
+	count, err := s.pg.MigrateUp(s.ctx)
+
+	s.NoError(err)
+	s.Equal(1, count)
+}
+
+func (s *MigrateSuite) TestMigrateDown_RollsBackLatest() {
+	migrations, err := loadMigrations()
+	s.Require().NoError(err)
+	last := migrations[len(migrations)-1]
+
+	applied := sqlmock.NewRows([]string{"version"})
+	for _, m := range migrations {
+		applied.AddRow(m.Version)
+	}
+	s.expectLock()
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM public.schema_migrations")).WillReturnRows(applied)
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(strings.TrimSpace(last.Down))).WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectExec(regexp.QuoteMeta("DELETE FROM public.schema_migrations")).
+		WithArgs(last.Version).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+	s.expectUnlock()
+
+	count, err := s.pg.MigrateDown(s.ctx, 1)
+
+	s.NoError(err)
+	s.Equal(1, count)
+}
+
+func (s *MigrateSuite) TestMigrateUp_BaselinesLegacySchema() {
+	migrations, err := loadMigrations()
+	s.Require().NoError(err)
+
+	s.expectLock()
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM public.schema_migrations")).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}))
+	// База из initdb с миграциями 001–003: нет popularity_updated_at и дальше
+	s.mock.ExpectQuery(regexp.QuoteMeta("to_regclass('public.users')")).
+		WillReturnRows(sqlmock.NewRows([]string{"users", "password_algo", "popularity", "popularity_updated_at", "search_trends", "outbox"}).
+			AddRow(true, true, true, false, true, false))
+	for _, m := range migrations[:3] {
+		s.mock.ExpectExec(regexp.QuoteMeta("ON CONFLICT (version) DO NOTHING")).
+			WithArgs(m.Version, m.Name).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	for _, m := range migrations[3:] {
+		s.mock.ExpectBegin()
+		s.mock.ExpectExec(regexp.QuoteMeta(strings.TrimSpace(m.Up))).WillReturnResult(sqlmock.NewResult(0, 0))
+		s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO public.schema_migrations")).
+			WithArgs(m.Version, m.Name).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		s.mock.ExpectCommit()
+	}
+	s.expectUnlock()
+
+	count, err := s.pg.MigrateUp(s.ctx)
+
+	s.NoError(err)
+	s.Equal(len(migrations)-3, count)
+}
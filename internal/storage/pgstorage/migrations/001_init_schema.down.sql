@@ -0,0 +1,9 @@
+--
+-- Удаление исходной схемы. Последовательности принадлежат колонкам и удаляются вместе с таблицами.
+--
+
+DROP TABLE public.users;
+DROP TABLE public.languages;
+DROP TABLE public.genres;
+DROP TABLE public.games;
+DROP TABLE public.apps;
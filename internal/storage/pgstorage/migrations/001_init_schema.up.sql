@@ -13,7 +13,7 @@ SET idle_in_transaction_session_timeout = 0;
 SET transaction_timeout = 0;
 SET client_encoding = 'UTF8';
 SET standard_conforming_strings = on;
-SELECT pg_catalog.set_config('search_path', '', false);
+SELECT pg_catalog.set_config('search_path', '', true);
 SET check_function_bodies = false;
 SET xmloption = content;
 SET client_min_messages = warning;
@@ -267,14 +267,12 @@ ALTER TABLE ONLY public.users ALTER COLUMN id SET DEFAULT nextval('public.users_
 -- Data for Name: apps; Type: TABLE DATA; Schema: public; Owner: teammate_search
 --
 
-COPY public.apps (id_app, app) FROM stdin;
-1	Discord
-2	TeamSpeak
-3	Zoom
-4	Telegram
-5	VK
-\.
-
+INSERT INTO public.apps (id_app, app) VALUES
+    (1, 'Discord'),
+    (2, 'TeamSpeak'),
+    (3, 'Zoom'),
+    (4, 'Telegram'),
+    (5, 'VK');
 
 --
 -- TOC entry 4884 (class 0 OID 19289)
@@ -282,22 +280,20 @@ COPY public.apps (id_app, app) FROM stdin;
 -- Data for Name: games; Type: TABLE DATA; Schema: public; Owner: teammate_search
 --
 
-COPY public.games (id_game, game) FROM stdin;
-1	League Of Legends
-2	DOTA2
-3	CS GO
-4	Far Cry
-5	R.E.P.O
-6	Raft
-7	RUST
-8	SUPERVIVE
-9	Maincraft
-10	The Forest
-11	PUBG
-12	PEAK
-13	Lethal Company
-\.
-
+INSERT INTO public.games (id_game, game) VALUES
+    (1, 'League Of Legends'),
+    (2, 'DOTA2'),
+    (3, 'CS GO'),
+    (4, 'Far Cry'),
+    (5, 'R.E.P.O'),
+    (6, 'Raft'),
+    (7, 'RUST'),
+    (8, 'SUPERVIVE'),
+    (9, 'Maincraft'),
+    (10, 'The Forest'),
+    (11, 'PUBG'),
+    (12, 'PEAK'),
+    (13, 'Lethal Company');
 
 --
 -- TOC entry 4882 (class 0 OID 19280)
@@ -305,36 +301,34 @@ COPY public.games (id_game, game) FROM stdin;
 -- Data for Name: genres; Type: TABLE DATA; Schema: public; Owner: teammate_search
 --
 
-COPY public.genres (id_genre, genre) FROM stdin;
-1	Шутер от первого лица
-2	Шутер от третьего лица
-3	Слэшеры
-4	Казуальные
-5	Ролевые экшены
-6	Стратегии
-7	Японские ролевые
-8	Симуляторы
-9	Башенная защита
-10	Спортивные симуляторы
-11	Гонки
-12	Хоррор
-13	Научная фантастика
-14	Космос
-15	Аркада
-16	Платформеры
-17	Файтинги
-18	Визуальные новеллы
-19	Приключения
-20	Песочницы
-21	Карточные
-22	Настольные
-23	Аниме
-24	Выживание
-25	Детективы
-26	Открытый мир
-27	Кооператив
-\.
-
+INSERT INTO public.genres (id_genre, genre) VALUES
+    (1, 'Шутер от первого лица'),
+    (2, 'Шутер от третьего лица'),
+    (3, 'Слэшеры'),
+    (4, 'Казуальные'),
+    (5, 'Ролевые экшены'),
+    (6, 'Стратегии'),
+    (7, 'Японские ролевые'),
+    (8, 'Симуляторы'),
+    (9, 'Башенная защита'),
+    (10, 'Спортивные симуляторы'),
+    (11, 'Гонки'),
+    (12, 'Хоррор'),
+    (13, 'Научная фантастика'),
+    (14, 'Космос'),
+    (15, 'Аркада'),
+    (16, 'Платформеры'),
+    (17, 'Файтинги'),
+    (18, 'Визуальные новеллы'),
+    (19, 'Приключения'),
+    (20, 'Песочницы'),
+    (21, 'Карточные'),
+    (22, 'Настольные'),
+    (23, 'Аниме'),
+    (24, 'Выживание'),
+    (25, 'Детективы'),
+    (26, 'Открытый мир'),
+    (27, 'Кооператив');
 
 --
 -- TOC entry 4886 (class 0 OID 19298)
@@ -342,33 +336,31 @@ COPY public.genres (id_genre, genre) FROM stdin;
 -- Data for Name: languages; Type: TABLE DATA; Schema: public; Owner: teammate_search
 --
 
-COPY public.languages (id_language, language) FROM stdin;
-1	Russian
-2	English
-3	German
-4	French
-5	Spanish
-6	Italian
-7	Portuguese
-8	Polish
-9	Belarusian
-10	Bulgarian
-11	Czech
-12	Slovak
-13	Slovenian
-14	Croatian
-15	Serbian
-16	Bosnian
-17	Macedonian
-18	Greek
-19	Hungarian
-20	Romanian
-21	Chinese
-22	Japanese
-23	Korean
-24	Arabic
-\.
-
+INSERT INTO public.languages (id_language, language) VALUES
+    (1, 'Russian'),
+    (2, 'English'),
+    (3, 'German'),
+    (4, 'French'),
+    (5, 'Spanish'),
+    (6, 'Italian'),
+    (7, 'Portuguese'),
+    (8, 'Polish'),
+    (9, 'Belarusian'),
+    (10, 'Bulgarian'),
+    (11, 'Czech'),
+    (12, 'Slovak'),
+    (13, 'Slovenian'),
+    (14, 'Croatian'),
+    (15, 'Serbian'),
+    (16, 'Bosnian'),
+    (17, 'Macedonian'),
+    (18, 'Greek'),
+    (19, 'Hungarian'),
+    (20, 'Romanian'),
+    (21, 'Chinese'),
+    (22, 'Japanese'),
+    (23, 'Korean'),
+    (24, 'Arabic');
 
 --
 -- TOC entry 4880 (class 0 OID 19271)
@@ -376,15 +368,13 @@ COPY public.languages (id_language, language) FROM stdin;
 -- Data for Name: users; Type: TABLE DATA; Schema: public; Owner: teammate_search
 --
 
-COPY public.users (id, username, password, age, description, most_like_game, most_like_genre, language, created_at, speaking_app) FROM stdin;
-2	Piryet	Piryet	33	хороший человек\r\n                                    \r\n                                    \r\n                                    \r\n                                    \r\n                                    \r\n                                    \r\n                                    	9	1	1	2025-12-21	1
-4	Aroman	Aroman	23	kungfu panda	1	1	1	2025-12-24	1
-3	CRIGO	CRIGO	23	crigo estriper	4	1	1	2025-12-22	1
-5	NewUser	NewUser	23	good guy	\N	\N	\N	2025-12-26	\N
-6	NewUser2	NewUser2	31	qwe	\N	\N	\N	2025-12-26	\N
-7	NewUser3	NewUser3	32	string	\N	\N	\N	2025-12-26	\N
-\.
-
+INSERT INTO public.users (id, username, password, age, description, most_like_game, most_like_genre, language, created_at, speaking_app) VALUES
+    (2, 'Piryet', 'Piryet', 33, E'хороший человек\r\n                                    \r\n                                    \r\n                                    \r\n                                    \r\n                                    \r\n                                    \r\n                                    ', 9, 1, 1, '2025-12-21', 1),
+    (4, 'Aroman', 'Aroman', 23, 'kungfu panda', 1, 1, 1, '2025-12-24', 1),
+    (3, 'CRIGO', 'CRIGO', 23, 'crigo estriper', 4, 1, 1, '2025-12-22', 1),
+    (5, 'NewUser', 'NewUser', 23, 'good guy', NULL, NULL, NULL, '2025-12-26', NULL),
+    (6, 'NewUser2', 'NewUser2', 31, 'qwe', NULL, NULL, NULL, '2025-12-26', NULL),
+    (7, 'NewUser3', 'NewUser3', 32, 'string', NULL, NULL, NULL, '2025-12-26', NULL);
 
 --
 -- TOC entry 4899 (class 0 OID 0)
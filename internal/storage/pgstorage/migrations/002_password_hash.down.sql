@@ -0,0 +1,6 @@
+--
+-- Пароли, уже перевыпущенные в bcrypt, останутся хешами: откат не возвращает открытый текст.
+--
+
+ALTER TABLE public.users
+    DROP COLUMN password_algo;
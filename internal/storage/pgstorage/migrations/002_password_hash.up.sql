@@ -0,0 +1,10 @@
+--
+-- Хранение паролей в виде хеша: колонка с алгоритмом, которым записан users.password.
+-- Существующие строки помечаются как 'plain' и перевыпускаются в bcrypt при следующем входе.
+--
+
+ALTER TABLE public.users
+    ADD COLUMN password_algo text NOT NULL DEFAULT 'plain';
+
+ALTER TABLE public.users
+    ALTER COLUMN password_algo SET DEFAULT 'bcrypt';
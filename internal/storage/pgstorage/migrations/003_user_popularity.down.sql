@@ -0,0 +1,4 @@
+DROP INDEX public.users_popularity_idx;
+
+ALTER TABLE public.users
+    DROP COLUMN popularity;
@@ -0,0 +1,8 @@
+--
+-- Рейтинг популярности пользователя для сортировки результатов поиска.
+--
+
+ALTER TABLE public.users
+    ADD COLUMN popularity double precision NOT NULL DEFAULT 0;
+
+CREATE INDEX users_popularity_idx ON public.users USING btree (popularity DESC, id DESC);
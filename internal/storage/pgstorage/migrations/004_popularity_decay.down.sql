@@ -0,0 +1,2 @@
+ALTER TABLE public.users
+    DROP COLUMN popularity_updated_at;
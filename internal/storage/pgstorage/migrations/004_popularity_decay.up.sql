@@ -0,0 +1,6 @@
+--
+-- Момент последнего пересчёта users.popularity, от него считается затухание.
+--
+
+ALTER TABLE public.users
+    ADD COLUMN popularity_updated_at timestamp with time zone NOT NULL DEFAULT now();
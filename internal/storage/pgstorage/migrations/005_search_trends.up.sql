@@ -0,0 +1,15 @@
+--
+-- Агрегаты поисковых запросов из топика filter.data: число поисков значения
+-- измерения (игра, жанр, язык, приложение, диапазон возраста) за час или день.
+--
+
+CREATE TABLE public.search_trends (
+    granularity text NOT NULL,
+    bucket_start timestamp with time zone NOT NULL,
+    dimension text NOT NULL,
+    value text NOT NULL,
+    count bigint NOT NULL DEFAULT 0,
+    CONSTRAINT search_trends_pkey PRIMARY KEY (granularity, bucket_start, dimension, value)
+);
+
+ALTER TABLE public.search_trends OWNER TO teammate_search;
@@ -0,0 +1,19 @@
+--
+-- Transactional outbox: события для Kafka пишутся в той же транзакции, что и
+-- бизнес-изменение, и отправляются фоновым релеем. Отправленные строки удаляются.
+--
+
+CREATE TABLE public.outbox (
+    id bigserial PRIMARY KEY,
+    topic text NOT NULL,
+    key text NOT NULL DEFAULT '',
+    payload bytea NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamp with time zone NOT NULL DEFAULT now(),
+    last_error text
+);
+
+CREATE INDEX outbox_next_attempt_idx ON public.outbox (next_attempt_at, id);
+
+ALTER TABLE public.outbox OWNER TO teammate_search;
@@ -0,0 +1 @@
+ALTER TABLE public.outbox DROP COLUMN request_id;
@@ -0,0 +1,6 @@
+--
+-- Идентификатор HTTP-запроса, породившего событие: релей передаёт его в заголовке
+-- X-Request-ID сообщения Kafka, чтобы события можно было связать с логами запроса.
+--
+
+ALTER TABLE public.outbox ADD COLUMN request_id text NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+DROP TABLE public.user_apps;
+DROP TABLE public.user_languages;
+DROP TABLE public.user_genres;
+DROP TABLE public.user_games;
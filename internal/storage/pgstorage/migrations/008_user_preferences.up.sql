@@ -0,0 +1,56 @@
+--
+-- Любимые игры, жанры, языки и приложения пользователя: связи многие-ко-многим
+-- со справочниками. priority задаёт порядок (0 — главное значение), главное значение
+-- дублируется в колонках users для совпадения профилей и событий старого формата.
+--
+
+CREATE TABLE public.user_games (
+    user_id integer NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    game_id integer NOT NULL REFERENCES public.games(id_game) ON DELETE CASCADE,
+    priority smallint NOT NULL DEFAULT 0,
+    CONSTRAINT user_games_pkey PRIMARY KEY (user_id, game_id)
+);
+
+CREATE TABLE public.user_genres (
+    user_id integer NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    genre_id integer NOT NULL REFERENCES public.genres(id_genre) ON DELETE CASCADE,
+    priority smallint NOT NULL DEFAULT 0,
+    CONSTRAINT user_genres_pkey PRIMARY KEY (user_id, genre_id)
+);
+
+CREATE TABLE public.user_languages (
+    user_id integer NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    language_id integer NOT NULL REFERENCES public.languages(id_language) ON DELETE CASCADE,
+    priority smallint NOT NULL DEFAULT 0,
+    CONSTRAINT user_languages_pkey PRIMARY KEY (user_id, language_id)
+);
+
+CREATE TABLE public.user_apps (
+    user_id integer NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    app_id integer NOT NULL REFERENCES public.apps(id_app) ON DELETE CASCADE,
+    priority smallint NOT NULL DEFAULT 0,
+    CONSTRAINT user_apps_pkey PRIMARY KEY (user_id, app_id)
+);
+
+-- Поиск идёт от значения справочника к пользователям
+CREATE INDEX user_games_game_id_idx ON public.user_games (game_id);
+CREATE INDEX user_genres_genre_id_idx ON public.user_genres (genre_id);
+CREATE INDEX user_languages_language_id_idx ON public.user_languages (language_id);
+CREATE INDEX user_apps_app_id_idx ON public.user_apps (app_id);
+
+ALTER TABLE public.user_games OWNER TO teammate_search;
+ALTER TABLE public.user_genres OWNER TO teammate_search;
+ALTER TABLE public.user_languages OWNER TO teammate_search;
+ALTER TABLE public.user_apps OWNER TO teammate_search;
+
+INSERT INTO public.user_games (user_id, game_id)
+SELECT u.id, u.most_like_game FROM public.users u JOIN public.games g ON g.id_game = u.most_like_game;
+
+INSERT INTO public.user_genres (user_id, genre_id)
+SELECT u.id, u.most_like_genre FROM public.users u JOIN public.genres g ON g.id_genre = u.most_like_genre;
+
+INSERT INTO public.user_languages (user_id, language_id)
+SELECT u.id, u.language FROM public.users u JOIN public.languages l ON l.id_language = u.language;
+
+INSERT INTO public.user_apps (user_id, app_id)
+SELECT u.id, u.speaking_app FROM public.users u JOIN public.apps a ON a.id_app = u.speaking_app;
@@ -0,0 +1,4 @@
+DROP INDEX public.users_description_tsv_idx;
+
+ALTER TABLE public.users
+    DROP COLUMN description_tsv;
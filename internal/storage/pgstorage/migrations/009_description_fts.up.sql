@@ -0,0 +1,12 @@
+--
+-- Полнотекстовый поиск по описанию профиля. Вектор строится в русской и английской
+-- конфигурациях, чтобы запросы находили словоформы на обоих языках.
+--
+
+ALTER TABLE public.users
+    ADD COLUMN description_tsv tsvector GENERATED ALWAYS AS (
+        to_tsvector('russian'::regconfig, COALESCE(description, ''))
+        || to_tsvector('english'::regconfig, COALESCE(description, ''))
+    ) STORED;
+
+CREATE INDEX users_description_tsv_idx ON public.users USING gin (description_tsv);
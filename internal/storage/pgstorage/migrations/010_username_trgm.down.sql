@@ -0,0 +1,2 @@
+-- Расширение pg_trgm не удаляется: им могут пользоваться другие объекты базы
+DROP INDEX public.users_username_trgm_idx;
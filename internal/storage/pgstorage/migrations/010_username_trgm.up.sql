@@ -0,0 +1,8 @@
+--
+-- Нечёткий поиск игроков по нику: триграммный индекс ускоряет и поиск по началу
+-- ника (LIKE), и поиск с опечатками (операторы pg_trgm).
+--
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm WITH SCHEMA public;
+
+CREATE INDEX users_username_trgm_idx ON public.users USING gin (lower(username) public.gin_trgm_ops);
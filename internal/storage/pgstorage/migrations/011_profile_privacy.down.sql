@@ -0,0 +1,4 @@
+ALTER TABLE public.users
+    DROP COLUMN hide_profile,
+    DROP COLUMN hide_age,
+    DROP COLUMN hide_popularity;
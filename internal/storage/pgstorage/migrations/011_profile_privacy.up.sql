@@ -0,0 +1,9 @@
+--
+-- Настройки приватности публичной страницы профиля /users/{username}.
+-- По умолчанию профиль виден полностью.
+--
+
+ALTER TABLE public.users
+    ADD COLUMN hide_profile boolean NOT NULL DEFAULT false,
+    ADD COLUMN hide_age boolean NOT NULL DEFAULT false,
+    ADD COLUMN hide_popularity boolean NOT NULL DEFAULT false;
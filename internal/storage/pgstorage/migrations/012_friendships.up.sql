@@ -0,0 +1,23 @@
+--
+-- Заявки в тиммейты. Пара игроков хранится одной строкой независимо от того,
+-- кто отправил заявку: pending — заявка ждёт ответа addressee, accepted — игроки тиммейты.
+-- Отклонённые, отменённые и удалённые связи удаляются, после этого заявку можно отправить снова.
+--
+
+CREATE TABLE public.friendships (
+    id bigserial PRIMARY KEY,
+    requester_id integer NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    addressee_id integer NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    status text NOT NULL DEFAULT 'pending',
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT friendships_status_check CHECK (status IN ('pending', 'accepted')),
+    CONSTRAINT friendships_not_self_check CHECK (requester_id <> addressee_id)
+);
+
+-- Одна связь на пару игроков: встречная заявка не создаёт вторую строку
+CREATE UNIQUE INDEX friendships_pair_idx
+    ON public.friendships (LEAST(requester_id, addressee_id), GREATEST(requester_id, addressee_id));
+
+CREATE INDEX friendships_requester_idx ON public.friendships (requester_id, status);
+CREATE INDEX friendships_addressee_idx ON public.friendships (addressee_id, status);
@@ -0,0 +1,2 @@
+ALTER TABLE public.users
+    DROP CONSTRAINT users_username_key;
@@ -0,0 +1,8 @@
+--
+-- Уникальность ника на уровне БД: проверка перед INSERT не защищает от двух
+-- одновременных регистраций с одним ником. Если в базе уже есть повторяющиеся ники,
+-- миграция завершится ошибкой — дубли нужно переименовать вручную.
+--
+
+ALTER TABLE public.users
+    ADD CONSTRAINT users_username_key UNIQUE (username);
@@ -0,0 +1,128 @@
+package pgstorage
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/DmitriySama/teammate_search/internal/logging"
+	"github.com/DmitriySama/teammate_search/internal/metrics"
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+// execer общий интерфейс *sql.DB и *sql.Tx, чтобы событие записывалось в транзакции бизнес-изменения
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// enqueueEvent записывает событие в outbox вместе с идентификатором запроса из ctx,
+// отправкой в Kafka занимается релей
+func enqueueEvent(ctx context.Context, q execer, topic, key string, payload []byte) error {
+	_, err := q.ExecContext(ctx, `
+        INSERT INTO outbox (topic, key, payload, request_id)
+        VALUES ($1, $2, $3, $4)
+    `, topic, key, payload, logging.RequestID(ctx))
+	return err
+}
+
+// outboxFinishTimeout время на удаление и перенос событий после отправки. Эти запросы
+// выполняются и после отмены контекста релея, иначе отправленные события ушли бы повторно
+const outboxFinishTimeout = 5 * time.Second
+
+// DrainOutbox захватывает до limit готовых к отправке событий и передаёт их в publish по порядку.
+// Захват — один короткий запрос: next_attempt_at сдвигается на lease, и до его истечения событие
+// не видят другие релеи, поэтому отправка идёт без открытой транзакции и блокировок строк.
+// Отправленные события удаляются одним запросом; при падении до удаления они уйдут повторно
+// после истечения аренды (at-least-once). На первой ошибке событие откладывается на backoff(attempts),
+// а с остальных аренда снимается, чтобы они ушли следующим проходом.
+// Возвращает число отправленных событий
+func (pg *PGstorage) DrainOutbox(ctx context.Context, limit int, lease time.Duration, publish func(context.Context, models.OutboxEvent) error, backoff func(attempts int) time.Duration) (int, error) {
+	defer metrics.ObserveDBQuery("DrainOutbox")()
+	events, err := pg.claimOutbox(ctx, limit, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	var sent []int64
+	var failed *models.OutboxEvent
+	var failErr error
+	for i := range events {
+		if failErr = publish(ctx, events[i]); failErr != nil {
+			failed = &events[i]
+			break
+		}
+		sent = append(sent, events[i].ID)
+	}
+
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), outboxFinishTimeout)
+	defer cancel()
+	if len(sent) > 0 {
+		if _, err := pg.DB.ExecContext(finishCtx, `DELETE FROM outbox WHERE id = ANY($1)`, pq.Int64Array(sent)); err != nil {
+			return 0, err
+		}
+	}
+	if failed == nil {
+		return len(sent), nil
+	}
+
+	attempts := failed.Attempts + 1
+	_, err = pg.DB.ExecContext(finishCtx, `
+        UPDATE outbox
+        SET attempts = $1,
+            next_attempt_at = now() + make_interval(secs => $2),
+            last_error = $3
+        WHERE id = $4
+    `, attempts, backoff(attempts).Seconds(), failErr.Error(), failed.ID)
+	if err != nil {
+		return len(sent), err
+	}
+	var rest []int64
+	for _, ev := range events[len(sent)+1:] {
+		rest = append(rest, ev.ID)
+	}
+	if len(rest) > 0 {
+		_, err = pg.DB.ExecContext(finishCtx, `UPDATE outbox SET next_attempt_at = now() WHERE id = ANY($1)`, pq.Int64Array(rest))
+	}
+	return len(sent), err
+}
+
+// claimOutbox берёт в аренду до limit готовых событий и возвращает их в порядке записи
+func (pg *PGstorage) claimOutbox(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	rows, err := pg.DB.QueryContext(ctx, `
+        UPDATE outbox o
+        SET next_attempt_at = now() + make_interval(secs => $2)
+        FROM (
+            SELECT id
+            FROM outbox
+            WHERE next_attempt_at <= now()
+            ORDER BY id
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+        ) due
+        WHERE o.id = due.id
+        RETURNING o.id, o.topic, o.key, o.payload, o.attempts, o.request_id
+    `, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.OutboxEvent
+	for rows.Next() {
+		var ev models.OutboxEvent
+		if err := rows.Scan(&ev.ID, &ev.Topic, &ev.Key, &ev.Payload, &ev.Attempts, &ev.RequestID); err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// RETURNING не гарантирует порядок строк
+	slices.SortFunc(events, func(a, b models.OutboxEvent) int { return cmp.Compare(a.ID, b.ID) })
+	return events, nil
+}
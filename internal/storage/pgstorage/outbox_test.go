@@ -0,0 +1,151 @@
+package pgstorage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/DmitriySama/teammate_search/config"
+	"github.com/DmitriySama/teammate_search/internal/logging"
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+var testTopics = config.TopicsConfig{
+	FilterData:       "test.filter.data",
+	UserPopularity:   "test.user.popularity",
+	UpdateUserData:   "test.update.user.data",
+	TeammateRequests: "test.teammate.requests",
+}
+
+type OutboxSuite struct {
+	suite.Suite
+	ctx  context.Context
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	pg   *PGstorage
+}
+
+func (s *OutboxSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+	s.ctx = context.Background()
+	s.db, s.mock = db, mock
+	s.pg = &PGstorage{DB: db, topics: testTopics}
+}
+
+func (s *OutboxSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+func TestOutboxSuite(t *testing.T) {
+	suite.Run(t, new(OutboxSuite))
+}
+
+func (s *OutboxSuite) TestFilterData_Enqueues() {
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox")).
+		WithArgs(testTopics.FilterData, "", []byte(`{"age0":18,"age1":0,"game":["2"],"genre":["-1"],"language":["-1"],"app":["-1"]}`), "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := s.pg.FilterData(s.ctx, models.FilterData{Age0: 18, Game: models.FilterValues{"2"}, Genre: models.FilterValues{"-1"}, Language: models.FilterValues{"-1"}, App: models.FilterValues{"-1"}})
+
+	s.NoError(err)
+}
+
+func (s *OutboxSuite) TestSelectUser_EnqueuesRequestID() {
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox")).
+		WithArgs(testTopics.UserPopularity, "player", []byte("player"), "req-42").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := s.pg.SelectUser(logging.WithRequestID(s.ctx, "req-42"), "player")
+
+	s.NoError(err)
+}
+
+func outboxRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "topic", "key", "payload", "attempts", "request_id"})
+}
+
+func (s *OutboxSuite) TestDrainOutbox_DeletesPublished() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+		WithArgs(10, float64(30)).
+		WillReturnRows(outboxRows().
+			AddRow(2, testTopics.FilterData, "", []byte("{}"), 2, "").
+			AddRow(1, testTopics.UserPopularity, "player", []byte("player"), 0, "req-1"))
+	s.mock.ExpectExec(regexp.QuoteMeta("DELETE FROM outbox WHERE id = ANY($1)")).
+		WithArgs(pq.Int64Array{1, 2}).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	var sent []int64
+	var requestIDs []string
+	published, err := s.pg.DrainOutbox(s.ctx, 10, 30*time.Second, func(_ context.Context, ev models.OutboxEvent) error {
+		sent = append(sent, ev.ID)
+		requestIDs = append(requestIDs, ev.RequestID)
+		return nil
+	}, func(int) time.Duration { return time.Second })
+
+	s.NoError(err)
+	s.Equal(2, published)
+	s.Equal([]int64{1, 2}, sent)
+	s.Equal([]string{"req-1", ""}, requestIDs)
+}
+
+func (s *OutboxSuite) TestDrainOutbox_ReschedulesFailedAndReleasesRest() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+		WithArgs(10, float64(30)).
+		WillReturnRows(outboxRows().
+			AddRow(1, testTopics.UserPopularity, "player", []byte("player"), 0, "").
+			AddRow(2, testTopics.UserPopularity, "other", []byte("other"), 2, "").
+			AddRow(3, testTopics.UserPopularity, "third", []byte("third"), 0, ""))
+	s.mock.ExpectExec(regexp.QuoteMeta("DELETE FROM outbox WHERE id = ANY($1)")).
+		WithArgs(pq.Int64Array{1}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta("last_error = $3")).
+		WithArgs(3, float64(4), "broker down", 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox SET next_attempt_at = now() WHERE id = ANY($1)")).
+		WithArgs(pq.Int64Array{3}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	calls := 0
+	published, err := s.pg.DrainOutbox(s.ctx, 10, 30*time.Second, func(_ context.Context, ev models.OutboxEvent) error {
+		calls++
+		if ev.ID == 2 {
+			return errors.New("broker down")
+		}
+		return nil
+	}, func(attempts int) time.Duration {
+		s.Equal(3, attempts)
+		return 4 * time.Second
+	})
+
+	s.NoError(err)
+	s.Equal(1, published)
+	s.Equal(2, calls)
+}
+
+func (s *OutboxSuite) TestDrainOutbox_FinishesAfterCancel() {
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+		WithArgs(10, float64(30)).
+		WillReturnRows(outboxRows().AddRow(1, testTopics.UserPopularity, "player", []byte("player"), 0, ""))
+	s.mock.ExpectExec(regexp.QuoteMeta("DELETE FROM outbox")).
+		WithArgs(pq.Int64Array{1}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	published, err := s.pg.DrainOutbox(ctx, 10, 30*time.Second, func(context.Context, models.OutboxEvent) error {
+		// Остановка приходит после успешной отправки: событие всё равно должно быть удалено
+		cancel()
+		return nil
+	}, func(int) time.Duration { return time.Second })
+
+	s.NoError(err)
+	s.Equal(1, published)
+}
@@ -0,0 +1,40 @@
+package pgstorage
+
+import (
+	"crypto/subtle"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Алгоритмы хранения пароля, значение колонки users.password_algo
+const (
+	passwordAlgoPlain  = "plain"
+	passwordAlgoBcrypt = "bcrypt"
+)
+
+// hashPassword возвращает bcrypt-хеш пароля, соль генерируется для каждого вызова
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// checkPassword сверяет пароль с сохранённым значением.
+// needsRehash сообщает, что значение нужно перевыпустить актуальным алгоритмом
+func checkPassword(stored, algo, password string) (ok bool, needsRehash bool) {
+	switch algo {
+	case passwordAlgoBcrypt:
+		if bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) != nil {
+			return false, false
+		}
+		cost, err := bcrypt.Cost([]byte(stored))
+		return true, err != nil || cost < bcrypt.DefaultCost
+	case passwordAlgoPlain:
+		ok := subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+		return ok, ok
+	default:
+		return false, false
+	}
+}
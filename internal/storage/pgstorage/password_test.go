@@ -0,0 +1,127 @@
+package pgstorage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcryptOf проверяет, что аргумент запроса является bcrypt-хешем пароля, а не самим паролем
+type bcryptOf string
+
+func (b bcryptOf) Match(v driver.Value) bool {
+	hash, ok := v.(string)
+	if !ok || hash == string(b) {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(b)) == nil
+}
+
+type PasswordSuite struct {
+	suite.Suite
+	ctx  context.Context
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	pg   *PGstorage
+}
+
+func (s *PasswordSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+	s.ctx = context.Background()
+	s.db, s.mock = db, mock
+	s.pg = &PGstorage{DB: db}
+}
+
+func (s *PasswordSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+func TestPasswordSuite(t *testing.T) {
+	suite.Run(t, new(PasswordSuite))
+}
+
+func (s *PasswordSuite) TestRegister_StoresHashOnly() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*)")).
+		WithArgs("player").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	s.mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO users")).
+		WithArgs("player", bcryptOf("secret123"), "desc", 20, sqlmock.AnyArg(), passwordAlgoBcrypt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	result, err := s.pg.Register(s.ctx, "player", "secret123", "desc", 20)
+
+	s.NoError(err)
+	s.True(result.Success)
+	s.NotEqual("secret123", result.User.Password)
+}
+
+func (s *PasswordSuite) TestRegister_ConcurrentDuplicate() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*)")).
+		WithArgs("player").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	s.mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO users")).
+		WillReturnError(&pq.Error{Code: uniqueViolation, Constraint: "users_username_key"})
+
+	result, err := s.pg.Register(s.ctx, "player", "secret123", "desc", 20)
+
+	s.NoError(err)
+	s.False(result.Success)
+}
+
+func (s *PasswordSuite) TestFindUser_Bcrypt() {
+	hash, err := hashPassword("secret123")
+	s.Require().NoError(err)
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT id, password, password_algo")).
+		WithArgs("player").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password", "password_algo"}).AddRow(3, hash, passwordAlgoBcrypt))
+
+	id, err := s.pg.FindUser(s.ctx, "player", "secret123")
+
+	s.NoError(err)
+	s.Equal(3, id)
+}
+
+func (s *PasswordSuite) TestFindUser_WrongPassword() {
+	hash, err := hashPassword("secret123")
+	s.Require().NoError(err)
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT id, password, password_algo")).
+		WithArgs("player").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password", "password_algo"}).AddRow(3, hash, passwordAlgoBcrypt))
+
+	_, err = s.pg.FindUser(s.ctx, "player", "wrong")
+
+	s.ErrorIs(err, sql.ErrNoRows)
+}
+
+func (s *PasswordSuite) TestFindUser_UpgradesPlaintext() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT id, password, password_algo")).
+		WithArgs("Aroman").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password", "password_algo"}).AddRow(4, "Aroman", passwordAlgoPlain))
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE users")).
+		WithArgs(bcryptOf("Aroman"), passwordAlgoBcrypt, 4, passwordAlgoPlain).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	id, err := s.pg.FindUser(s.ctx, "Aroman", "Aroman")
+
+	s.NoError(err)
+	s.Equal(4, id)
+}
+
+func (s *PasswordSuite) TestFindUser_PlaintextMismatchNotUpgraded() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT id, password, password_algo")).
+		WithArgs("Aroman").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password", "password_algo"}).AddRow(4, "Aroman", passwordAlgoPlain))
+
+	_, err := s.pg.FindUser(s.ctx, "Aroman", "guess")
+
+	s.ErrorIs(err, sql.ErrNoRows)
+}
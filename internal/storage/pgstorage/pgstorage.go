@@ -4,44 +4,34 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/DmitriySama/teammate_search/config"
-	"github.com/DmitriySama/teammate_search/internal/producer"
 	_ "github.com/lib/pq"
+	"github.com/rs/zerolog"
 )
 
 // PGstorage содержит бизнес-логику авторизации
 type PGstorage struct {
     DB *sql.DB
-    producer *producer.Manager
+    // topics топики Kafka, в которые уходят события из outbox
+    topics config.TopicsConfig
 }
 
 
-func InitDB(connString string, producer *producer.Manager) (*PGstorage, error) {
-    log.Println("Инициализация подключения к БД...")
-    
-    // 1. Загрузка конфигурации
-    cfg, err := config.LoadConfig()
-    if err != nil {
-        log.Printf("❌ Ошибка загрузки конфигурации: %v", err)
-        return nil, fmt.Errorf("не удалось загрузить конфигурацию: %w", err)
-    }
-    
-    if cfg == nil {
-        log.Println("❌ Конфигурация загружена, но cfg == nil")
-        return nil, fmt.Errorf("конфигурация не загружена (nil)")
-    }
-    
-    log.Printf("✅ Конфиг загружен. База данных: %s", cfg.Database.DBName)
- 
+// InitDB открывает пул соединений и проверяет подключение. Логи пишутся в логгер ctx
+func InitDB(ctx context.Context, cfg config.DatabaseConfig, topics config.TopicsConfig) (*PGstorage, error) {
+    logger := zerolog.Ctx(ctx).With().
+        Str("host", cfg.Host).
+        Int("port", cfg.Port).
+        Str("user", cfg.Username).
+        Str("dbname", cfg.DBName).
+        Logger()
+    logger.Info().Msg("Открытие соединения с PostgreSQL")
     
     // 4. Подключение к базе данных
-    log.Println("Открытие соединения с PostgreSQL...")
-    db, err := sql.Open("postgres", connString)
+    db, err := sql.Open("postgres", cfg.DatabaseURL())
     if err != nil {
-        log.Printf("❌ Ошибка открытия соединения: %v", err)
         return nil, fmt.Errorf("не удалось открыть соединение с БД: %w", err)
     }
     
@@ -52,33 +42,30 @@ func InitDB(connString string, producer *producer.Manager) (*PGstorage, error) {
     db.SetConnMaxIdleTime(2 * time.Minute)
     
     // 6. Проверка подключения
-    log.Println("Проверка подключения к БД (Ping)...")
-    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    pingCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
     defer cancel()
     
-    if err := db.PingContext(ctx); err != nil {
+    if err := db.PingContext(pingCtx); err != nil {
         db.Close()
-        log.Printf("❌ Ошибка подключения к БД: %v", err)
-        
-        // Детализация ошибки
-        log.Printf("Параметры подключения: host=%s, port=%d, user=%s, dbname=%s", 
-            cfg.Database.Host, cfg.Database.Port, cfg.Database.Username, cfg.Database.DBName)
-        
+        logger.Error().Err(err).Msg("Ошибка подключения к БД")
         return nil, fmt.Errorf("не удалось подключиться к БД: %w", err)
     }
 
     storage := &PGstorage{
         DB: db,
-        producer: producer,
+        topics: topics,
     }
     
-    log.Printf("✅ Успешно подключено к БД: %s@%s:%d/%s", 
-        cfg.Database.Username, cfg.Database.Host, cfg.Database.Port, cfg.Database.DBName)
+    logger.Info().Msg("Успешно подключено к БД")
     
     return storage, nil
 }
 
+// Ping проверяет соединение с PostgreSQL
+func (pg *PGstorage) Ping(ctx context.Context) error {
+    return pg.DB.PingContext(ctx)
+}
+
 func (db PGstorage) Close() error {
-    log.Println("Закрытие подключения к БД")
     return db.DB.Close()
 }
\ No newline at end of file
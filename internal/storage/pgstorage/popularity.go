@@ -0,0 +1,52 @@
+package pgstorage
+
+import (
+	"context"
+	"time"
+
+	"github.com/DmitriySama/teammate_search/internal/metrics"
+)
+
+// Затухание экспоненциальное: за halfLife очки уменьшаются вдвое.
+// $1 — момент времени, $2 — период полураспада в секундах
+const decayedPopularityExpr = `popularity * exp(-ln(2) * GREATEST(extract(epoch from ($1::timestamptz - popularity_updated_at)), 0) / $2)`
+
+// AddPopularity начисляет пользователям очки за просмотры с учётом затухания накопленного значения
+func (pg *PGstorage) AddPopularity(ctx context.Context, hits map[string]int, at time.Time, halfLife time.Duration) error {
+    defer metrics.ObserveDBQuery("AddPopularity")()
+    tx, err := pg.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    stmt, err := tx.PrepareContext(ctx, `
+        UPDATE users
+        SET popularity = `+decayedPopularityExpr+` + $3,
+            popularity_updated_at = $1
+        WHERE username = $4
+    `)
+    if err != nil {
+        return err
+    }
+    defer stmt.Close()
+
+    for username, count := range hits {
+        if _, err := stmt.ExecContext(ctx, at, halfLife.Seconds(), count, username); err != nil {
+            return err
+        }
+    }
+    return tx.Commit()
+}
+
+// DecayPopularity приводит популярность всех пользователей к моменту at
+func (pg *PGstorage) DecayPopularity(ctx context.Context, at time.Time, halfLife time.Duration) error {
+    defer metrics.ObserveDBQuery("DecayPopularity")()
+    _, err := pg.DB.ExecContext(ctx, `
+        UPDATE users
+        SET popularity = `+decayedPopularityExpr+`,
+            popularity_updated_at = $1
+        WHERE popularity > 0
+    `, at, halfLife.Seconds())
+    return err
+}
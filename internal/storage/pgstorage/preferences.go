@@ -0,0 +1,128 @@
+package pgstorage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+// querier общий интерфейс *sql.DB и *sql.Tx для чтения
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// preferenceTable связь пользователя со справочником многие-ко-многим
+type preferenceTable struct {
+	name     string // измерение, совпадает с именем поля фильтра
+	table    string
+	column   string
+	dict     string
+	dictID   string
+	dictName string
+}
+
+var preferenceTables = []preferenceTable{
+	{name: "game", table: "user_games", column: "game_id", dict: "games", dictID: "id_game", dictName: "game"},
+	{name: "genre", table: "user_genres", column: "genre_id", dict: "genres", dictID: "id_genre", dictName: "genre"},
+	{name: "language", table: "user_languages", column: "language_id", dict: "languages", dictID: "id_language", dictName: "language"},
+	{name: "app", table: "user_apps", column: "app_id", dict: "apps", dictID: "id_app", dictName: "app"},
+}
+
+// ids возвращает список ID измерения в предпочтениях
+func (t preferenceTable) ids(p *models.Preferences) *[]int {
+	switch t.name {
+	case "game":
+		return &p.GameIDs
+	case "genre":
+		return &p.GenreIDs
+	case "language":
+		return &p.LanguageIDs
+	default:
+		return &p.AppIDs
+	}
+}
+
+// names возвращает список названий измерения у пользователя
+func (t preferenceTable) names(u *models.User) *[]string {
+	switch t.name {
+	case "game":
+		return &u.Games
+	case "genre":
+		return &u.Genres
+	case "language":
+		return &u.Languages
+	default:
+		return &u.Apps
+	}
+}
+
+// preferencesQuery выбирает все предпочтения пользователя одним запросом
+var preferencesQuery = func() string {
+	parts := make([]string, 0, len(preferenceTables))
+	for _, t := range preferenceTables {
+		parts = append(parts, fmt.Sprintf(
+			"SELECT '%s' AS kind, p.%s AS id, d.%s AS name, p.priority FROM %s p JOIN %s d ON d.%s = p.%s WHERE p.user_id = $1",
+			t.name, t.column, t.dictName, t.table, t.dict, t.dictID, t.column))
+	}
+	return strings.Join(parts, "\nUNION ALL ") + "\nORDER BY kind, priority, id"
+}()
+
+// loadPreferences заполняет ID и названия предпочтений пользователя
+func loadPreferences(ctx context.Context, q querier, user *models.User) error {
+	rows, err := q.QueryContext(ctx, preferencesQuery, user.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	tables := make(map[string]preferenceTable, len(preferenceTables))
+	for _, t := range preferenceTables {
+		tables[t.name] = t
+	}
+	for rows.Next() {
+		var kind, name string
+		var id, priority int
+		if err := rows.Scan(&kind, &id, &name, &priority); err != nil {
+			return err
+		}
+		t, ok := tables[kind]
+		if !ok {
+			continue
+		}
+		ids, names := t.ids(&user.Preferences), t.names(user)
+		*ids = append(*ids, id)
+		*names = append(*names, name)
+	}
+	return rows.Err()
+}
+
+// replacePreferences заменяет предпочтения пользователя, порядок списков задаёт приоритет
+func replacePreferences(ctx context.Context, tx *sql.Tx, userID int, prefs models.Preferences) error {
+	for _, t := range preferenceTables {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE user_id = $1", t.table), userID); err != nil {
+			return err
+		}
+		ids := *t.ids(&prefs)
+		if len(ids) == 0 {
+			continue
+		}
+		values := make(pq.Int64Array, len(ids))
+		for i, id := range ids {
+			values[i] = int64(id)
+		}
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(`
+            INSERT INTO %s (user_id, %s, priority)
+            SELECT $1, v.id, v.ord - 1 FROM unnest($2::int[]) WITH ORDINALITY AS v(id, ord)`, t.table, t.column),
+			userID, values)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package pgstorage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+type PreferencesSuite struct {
+	suite.Suite
+	ctx  context.Context
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	pg   *PGstorage
+}
+
+func (s *PreferencesSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+	s.ctx = context.Background()
+	s.db, s.mock = db, mock
+	s.pg = &PGstorage{DB: db, topics: testTopics}
+}
+
+func (s *PreferencesSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+func TestPreferencesSuite(t *testing.T) {
+	suite.Run(t, new(PreferencesSuite))
+}
+
+// updateUserDataEvent проверяет, что аргумент — JSON события UpdateUserData с нужными полями
+type updateUserDataEvent struct {
+	userID  int
+	changed []string
+}
+
+func (e updateUserDataEvent) Match(v driver.Value) bool {
+	data, ok := v.([]byte)
+	if !ok {
+		return false
+	}
+	var ev models.UpdateUserData
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return false
+	}
+	return ev.Version == models.UpdateUserDataVersion && ev.UserID == e.userID &&
+		reflect.DeepEqual(ev.Changed, e.changed)
+}
+
+func profileRows(age int, description string, game, genre, app, language int, hideAge bool) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"age", "description", "game", "genre", "app", "language", "hide_profile", "hide_age", "hide_popularity"}).
+		AddRow(age, description, game, genre, app, language, false, hideAge, false)
+}
+
+func userRow(id int, username string, age int, description, game string, hideAge bool) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "username", "password", "age", "description", "created_at", "popularity",
+		"hide_profile", "hide_age", "hide_popularity", "f_game", "f_genre", "app", "lang"}).
+		AddRow(id, username, "hash", age, description, time.Time{}, 0.0, false, hideAge, false, game, "", "", "")
+}
+
+// preferenceRows строки запроса предпочтений одного справочника в порядке приоритета
+func preferenceRows(kind string, ids ...int) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"kind", "id", "name", "priority"})
+	for i, id := range ids {
+		rows.AddRow(kind, id, fmt.Sprintf("%s-%d", kind, id), i)
+	}
+	return rows
+}
+
+func (s *PreferencesSuite) expectPreferences(userID int, rows *sqlmock.Rows) {
+	s.mock.ExpectQuery(regexp.QuoteMeta("FROM user_games")).WithArgs(userID).WillReturnRows(rows)
+}
+
+func (s *PreferencesSuite) expectReplacePreferences(userID int, prefs models.Preferences) {
+	for _, t := range preferenceTables {
+		s.mock.ExpectExec(regexp.QuoteMeta("DELETE FROM " + t.table)).WithArgs(userID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		ids := *t.ids(&prefs)
+		if len(ids) == 0 {
+			continue
+		}
+		values := make(pq.Int64Array, len(ids))
+		for i, id := range ids {
+			values[i] = int64(id)
+		}
+		s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO "+t.table)).WithArgs(userID, values).
+			WillReturnResult(sqlmock.NewResult(0, int64(len(ids))))
+	}
+}
+
+func (s *PreferencesSuite) TestUpdateUser_EnqueuesDiff() {
+	upd := models.UserUpdate{Age: 21, Description: "new", GameID: 3, GenreID: 1, AppID: 2, LanguageID: 1,
+		Preferences: models.Preferences{GameIDs: []int{3, 5}}, PrivacyUpdate: models.Privacy{HideAge: true}.Update()}
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE")).WithArgs(7).
+		WillReturnRows(profileRows(20, "old", 3, 1, 2, 1, false))
+	s.expectPreferences(7, preferenceRows("game", 3))
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE users")).
+		WithArgs(21, "new", 3, 1, 1, 2, false, true, false, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.expectReplacePreferences(7, upd.Preferences)
+	s.mock.ExpectQuery(regexp.QuoteMeta("FROM users")).WithArgs(7).
+		WillReturnRows(profileRows(21, "new", 3, 1, 2, 1, true))
+	s.expectPreferences(7, preferenceRows("game", 3, 5))
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox")).
+		WithArgs(testTopics.UpdateUserData, "7", updateUserDataEvent{userID: 7, changed: []string{"age", "description", "hide_age", "game_ids"}}, "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectQuery(regexp.QuoteMeta("LEFT JOIN games")).WithArgs(7).
+		WillReturnRows(userRow(7, "player", 21, "new", "DOTA2", true))
+	s.expectPreferences(7, preferenceRows("game", 3, 5))
+	s.mock.ExpectCommit()
+
+	user, err := s.pg.UpdateUser(s.ctx, 7, upd)
+
+	s.NoError(err)
+	s.Equal(21, user.Age)
+	s.Equal("DOTA2", user.MostLikeGame)
+	s.Equal([]int{3, 5}, user.GameIDs)
+	s.Equal([]string{"game-3", "game-5"}, user.Games)
+	s.True(user.HideAge)
+}
+
+func (s *PreferencesSuite) TestUpdateUser_OmittedPrivacyKept() {
+	upd := models.UserUpdate{Age: 21, Description: "same"}
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE")).WithArgs(7).
+		WillReturnRows(profileRows(20, "same", 0, 0, 0, 0, true))
+	s.expectPreferences(7, preferenceRows("game"))
+	s.mock.ExpectExec(regexp.QuoteMeta("hide_age = COALESCE($8, hide_age)")).
+		WithArgs(21, "same", 0, 0, 0, 0, nil, nil, nil, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.expectReplacePreferences(7, upd.Preferences)
+	s.mock.ExpectQuery(regexp.QuoteMeta("FROM users")).WithArgs(7).
+		WillReturnRows(profileRows(21, "same", 0, 0, 0, 0, true))
+	s.expectPreferences(7, preferenceRows("game"))
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox")).
+		WithArgs(testTopics.UpdateUserData, "7", updateUserDataEvent{userID: 7, changed: []string{"age"}}, "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectQuery(regexp.QuoteMeta("LEFT JOIN games")).WithArgs(7).
+		WillReturnRows(userRow(7, "player", 21, "same", "", true))
+	s.expectPreferences(7, preferenceRows("game"))
+	s.mock.ExpectCommit()
+
+	user, err := s.pg.UpdateUser(s.ctx, 7, upd)
+
+	s.NoError(err)
+	s.True(user.HideAge)
+}
+
+func (s *PreferencesSuite) TestUpdateUser_NoChangesNoEvent() {
+	upd := models.UserUpdate{Age: 20, Description: "same"}
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE")).WithArgs(7).
+		WillReturnRows(profileRows(20, "same", 0, 0, 0, 0, false))
+	s.expectPreferences(7, preferenceRows("game"))
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE users")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.expectReplacePreferences(7, upd.Preferences)
+	s.mock.ExpectQuery(regexp.QuoteMeta("FROM users")).WithArgs(7).
+		WillReturnRows(profileRows(20, "same", 0, 0, 0, 0, false))
+	s.expectPreferences(7, preferenceRows("game"))
+	s.mock.ExpectQuery(regexp.QuoteMeta("LEFT JOIN games")).WithArgs(7).
+		WillReturnRows(userRow(7, "player", 20, "same", "", false))
+	s.expectPreferences(7, preferenceRows("game"))
+	s.mock.ExpectCommit()
+
+	_, err := s.pg.UpdateUser(s.ctx, 7, upd)
+
+	s.NoError(err)
+}
+
+func (s *PreferencesSuite) TestUpdateUser_NotFound() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE")).WithArgs(7).
+		WillReturnError(sql.ErrNoRows)
+	s.mock.ExpectRollback()
+
+	_, err := s.pg.UpdateUser(s.ctx, 7, models.UserUpdate{Age: 20})
+
+	s.ErrorIs(err, ErrUserNotFound)
+}
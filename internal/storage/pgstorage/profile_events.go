@@ -0,0 +1,134 @@
+package pgstorage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+// updateUserWithEvent выполняет update в транзакции и, если профиль изменился,
+// ставит в outbox событие UpdateUserData со значениями до и после изменения.
+// Возвращает пользователя, прочитанного в той же транзакции
+func (pg *PGstorage) updateUserWithEvent(ctx context.Context, userID int, update func(tx *sql.Tx) error) (*models.User, error) {
+	tx, err := pg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := profileSnapshot(ctx, tx, userID, true)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := update(tx); err != nil {
+		return nil, err
+	}
+	after, err := profileSnapshot(ctx, tx, userID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if changed := changedProfileFields(before, after); len(changed) > 0 {
+		data, err := json.Marshal(models.UpdateUserData{
+			Version:     models.UpdateUserDataVersion,
+			UserID:      userID,
+			ChangedAt:   time.Now().UTC(),
+			Changed:     changed,
+			UserDataOld: before,
+			UserDataNew: after,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := enqueueEvent(ctx, tx, pg.topics.UpdateUserData, strconv.Itoa(userID), data); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := getUserByID(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// profileSnapshot читает редактируемые поля профиля; forUpdate блокирует строку до конца транзакции
+func profileSnapshot(ctx context.Context, tx *sql.Tx, userID int, forUpdate bool) (models.UserUpdate, error) {
+	query := `
+        SELECT COALESCE(age, 0), COALESCE(description, ''),
+               COALESCE(most_like_game, 0), COALESCE(most_like_genre, 0),
+               COALESCE(speaking_app, 0), COALESCE(language, 0),
+               hide_profile, hide_age, hide_popularity
+        FROM users
+        WHERE id = $1`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	var u models.UserUpdate
+	var privacy models.Privacy
+	err := tx.QueryRowContext(ctx, query, userID).
+		Scan(&u.Age, &u.Description, &u.GameID, &u.GenreID, &u.AppID, &u.LanguageID,
+			&privacy.HideProfile, &privacy.HideAge, &privacy.HidePopularity)
+	if err != nil {
+		return u, err
+	}
+	u.PrivacyUpdate = privacy.Update()
+	user := models.User{ID: userID}
+	if err := loadPreferences(ctx, tx, &user); err != nil {
+		return u, err
+	}
+	u.Preferences = user.Preferences
+	return u, nil
+}
+
+// changedProfileFields возвращает JSON-имена полей, значения которых различаются
+func changedProfileFields(before, after models.UserUpdate) []string {
+	var changed []string
+	if before.Age != after.Age {
+		changed = append(changed, "age")
+	}
+	if before.Description != after.Description {
+		changed = append(changed, "description")
+	}
+	if before.GameID != after.GameID {
+		changed = append(changed, "game_id")
+	}
+	if before.GenreID != after.GenreID {
+		changed = append(changed, "genre_id")
+	}
+	if before.AppID != after.AppID {
+		changed = append(changed, "app_id")
+	}
+	if before.LanguageID != after.LanguageID {
+		changed = append(changed, "language_id")
+	}
+	oldPrivacy, newPrivacy := before.Apply(models.Privacy{}), after.Apply(models.Privacy{})
+	if oldPrivacy.HideProfile != newPrivacy.HideProfile {
+		changed = append(changed, "hide_profile")
+	}
+	if oldPrivacy.HideAge != newPrivacy.HideAge {
+		changed = append(changed, "hide_age")
+	}
+	if oldPrivacy.HidePopularity != newPrivacy.HidePopularity {
+		changed = append(changed, "hide_popularity")
+	}
+	for _, t := range preferenceTables {
+		if !slices.Equal(*t.ids(&before.Preferences), *t.ids(&after.Preferences)) {
+			changed = append(changed, t.name+"_ids")
+		}
+	}
+	return changed
+}
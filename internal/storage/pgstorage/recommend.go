@@ -0,0 +1,88 @@
+package pgstorage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/DmitriySama/teammate_search/internal/metrics"
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+// recommendationCandidatesQuery выбирает кандидатов в рекомендации вместе со списками
+// предпочтений. Первыми идут пользователи, у которых есть хотя бы одно общее
+// предпочтение со смотрящим ($1), затем самые популярные. Скрытые профили
+// не рекомендуются, скрытые возраст и популярность приходят нулём и не влияют на оценку
+var recommendationCandidatesQuery = func() string {
+	var lists, shared []string
+	for _, t := range preferenceTables {
+		lists = append(lists, fmt.Sprintf(
+			"ARRAY(SELECT p.%s FROM %s p WHERE p.user_id = u.id ORDER BY p.priority) AS %s_ids",
+			t.column, t.table, t.name))
+		shared = append(shared, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM %s p JOIN %s m ON m.%s = p.%s AND m.user_id = $1 WHERE p.user_id = u.id)",
+			t.table, t.table, t.column, t.column))
+	}
+	return fmt.Sprintf(`
+        SELECT
+            u.id,
+            u.username,
+            %s AS age,
+            COALESCE(u.description, '') AS description,
+            %s AS popularity,
+
+            COALESCE(g1.game, '') AS f_game,
+            COALESCE(g.genre, '') AS f_genre,
+            COALESCE(a.app, '') AS app,
+            COALESCE(l.language, '') AS lang,
+            %s
+        FROM users u
+        LEFT JOIN genres g ON u.most_like_genre = g.id_genre
+        LEFT JOIN languages l ON u.language = l.id_language
+        LEFT JOIN apps a ON u.speaking_app = a.id_app
+        LEFT JOIN games g1 ON u.most_like_game = g1.id_game
+        WHERE u.id <> $1 AND %s
+        ORDER BY (%s) DESC, %s DESC, u.id
+        LIMIT $2`,
+		publicAgeExpr, publicPopularityExpr, strings.Join(lists, ",\n            "),
+		searchVisibleCond, strings.Join(shared, "\n            OR "), publicPopularityExpr)
+}()
+
+// RecommendationCandidates возвращает до limit кандидатов в тиммейты для пользователя userID
+// с ID предпочтений в порядке приоритета. Оценка совместимости считается в сервисе
+func (pg *PGstorage) RecommendationCandidates(ctx context.Context, userID, limit int) ([]models.User, error) {
+	defer metrics.ObserveDBQuery("RecommendationCandidates")()
+	rows, err := pg.DB.QueryContext(ctx, recommendationCandidatesQuery, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []models.User
+	for rows.Next() {
+		var u models.User
+		var games, genres, languages, apps pq.Int64Array
+		if err := rows.Scan(&u.ID, &u.Username, &u.Age, &u.Description, &u.Popularity,
+			&u.MostLikeGame, &u.MostLikeGenre, &u.App, &u.Language,
+			&games, &genres, &languages, &apps); err != nil {
+			return nil, err
+		}
+		u.GameIDs, u.GenreIDs = intSlice(games), intSlice(genres)
+		u.LanguageIDs, u.AppIDs = intSlice(languages), intSlice(apps)
+		candidates = append(candidates, u)
+	}
+	return candidates, rows.Err()
+}
+
+func intSlice(values pq.Int64Array) []int {
+	if len(values) == 0 {
+		return nil
+	}
+	ids := make([]int, len(values))
+	for i, v := range values {
+		ids[i] = int(v)
+	}
+	return ids
+}
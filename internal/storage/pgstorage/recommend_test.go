@@ -0,0 +1,60 @@
+package pgstorage
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecommendSuite struct {
+	suite.Suite
+	ctx  context.Context
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	pg   *PGstorage
+}
+
+func (s *RecommendSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+	s.ctx = context.Background()
+	s.db, s.mock = db, mock
+	s.pg = &PGstorage{DB: db}
+}
+
+func (s *RecommendSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+func TestRecommendSuite(t *testing.T) {
+	suite.Run(t, new(RecommendSuite))
+}
+
+func (s *RecommendSuite) TestCandidatesQuery_RespectsPrivacy() {
+	s.Contains(recommendationCandidatesQuery, "WHERE u.id <> $1 AND NOT u.hide_profile")
+	s.Contains(recommendationCandidatesQuery, publicAgeExpr+" AS age")
+	s.Contains(recommendationCandidatesQuery, publicPopularityExpr+" AS popularity")
+}
+
+func (s *RecommendSuite) TestRecommendationCandidates_Scans() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("NOT u.hide_profile")).WithArgs(7, 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "age", "description", "popularity",
+			"f_game", "f_genre", "app", "lang", "game_ids", "genre_ids", "language_ids", "app_ids"}).
+			AddRow(3, "player", 0, "", 0.0, "DOTA2", "", "", "", pq.Int64Array{2, 1}, pq.Int64Array{}, pq.Int64Array{}, pq.Int64Array{4}))
+
+	candidates, err := s.pg.RecommendationCandidates(s.ctx, 7, 50)
+
+	s.Require().NoError(err)
+	s.Require().Len(candidates, 1)
+	s.Equal("player", candidates[0].Username)
+	s.Zero(candidates[0].Age)
+	s.Equal([]int{2, 1}, candidates[0].GameIDs)
+	s.Nil(candidates[0].GenreIDs)
+	s.Equal([]int{4}, candidates[0].AppIDs)
+}
@@ -0,0 +1,330 @@
+package pgstorage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/DmitriySama/teammate_search/internal/apperr"
+	"github.com/DmitriySama/teammate_search/internal/metrics"
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+// anyFilterValue значение фильтра «Любой» из формы поиска
+const anyFilterValue = "-1"
+
+// ErrInvalidSearch возвращается для некорректного фильтра или порядка сортировки
+var ErrInvalidSearch = apperr.Validation("некорректные параметры поиска")
+
+// Выражения возраста и популярности, какими их видят другие игроки: скрытые
+// настройками приватности значения отдаются нулём
+const (
+	publicAgeExpr        = "CASE WHEN u.hide_age THEN 0 ELSE COALESCE(u.age, 0) END"
+	publicPopularityExpr = "CASE WHEN u.hide_popularity THEN 0 ELSE u.popularity END"
+)
+
+// Параметр $1 всегда ID смотрящего пользователя: по нему считается совпадение профилей.
+// Подстановки: ключ сортировки и выражение фрагмента описания
+const searchUsersSelect = `
+        SELECT 
+            u.id,
+            (%s)::double precision AS sort_key,
+            u.username, 
+            ` + publicAgeExpr + ` AS age, 
+            COALESCE(u.description, '') AS description, 
+            %s AS snippet,
+            ` + publicPopularityExpr + ` AS popularity,
+            
+            COALESCE(g1.game, '') AS f_game,
+            COALESCE(g.genre, '') AS f_genre,
+            COALESCE(a.app, '') AS app,
+            COALESCE(l.language, '') AS lang
+        FROM users u
+        LEFT JOIN genres g ON u.most_like_genre = g.id_genre
+        LEFT JOIN languages l ON u.language = l.id_language
+        LEFT JOIN apps a ON u.speaking_app = a.id_app
+        LEFT JOIN games g1 ON u.most_like_game = g1.id_game`
+
+// searchVisibleCond скрытые профили не попадают в поиск
+const searchVisibleCond = "NOT u.hide_profile"
+
+// matchScoreExpr число общих со смотрящим предпочтений по всем справочникам
+var matchScoreExpr = func() string {
+	var shared []string
+	for _, t := range preferenceTables {
+		shared = append(shared, fmt.Sprintf(
+			"(SELECT count(*) FROM %s p JOIN %s m ON m.%s = p.%s AND m.user_id = $1 WHERE p.user_id = u.id)",
+			t.table, t.table, t.column, t.column))
+	}
+	return strings.Join(shared, "\n            + ")
+}()
+
+// textQueryJoin подставляет текстовый запрос ($%d) как tsquery в русской и английской
+// конфигурациях, они же используются в users.description_tsv
+const textQueryJoin = `
+        CROSS JOIN (SELECT websearch_to_tsquery('russian', $%[1]d) || websearch_to_tsquery('english', $%[1]d) AS query) fts`
+
+// Границы подсвеченных слов во фрагменте описания: управляющие символы не встречаются
+// в описаниях, а разметку подсветки строит шаблон с экранированием текста
+const (
+	snippetStartSel = "\x01"
+	snippetStopSel  = "\x02"
+)
+
+// snippetExpr фрагмент описания с подсвеченными словами запроса
+const snippetExpr = `ts_headline('russian', COALESCE(u.description, ''), fts.query,
+                'StartSel=' || chr(1) || ', StopSel=' || chr(2) || ', MaxWords=25, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "')`
+
+// sortSpec описывает ключ сортировки: выражение и направление, u.id добавляется для стабильности
+type sortSpec struct {
+	key     string
+	desc    bool
+	integer bool
+}
+
+var searchSorts = map[string]sortSpec{
+	models.SortNewest:     {key: "u.id", desc: true, integer: true},
+	models.SortAge:        {key: publicAgeExpr, desc: false, integer: true},
+	models.SortPopularity: {key: publicPopularityExpr, desc: true},
+	models.SortBestMatch:  {key: matchScoreExpr, desc: true, integer: true},
+	models.SortRelevance:  {key: "ts_rank(u.description_tsv, fts.query)", desc: true},
+}
+
+// searchQuery накапливает условия WHERE и их параметры
+type searchQuery struct {
+	sort  sortSpec
+	join  string
+	text  bool
+	where []string
+	args  []interface{}
+	order string
+	limit int
+}
+
+// add добавляет условие, cond содержит %d под номер параметра
+func (q *searchQuery) add(cond string, arg interface{}) {
+	q.args = append(q.args, arg)
+	q.where = append(q.where, fmt.Sprintf(cond, len(q.args)))
+}
+
+// addPreference добавляет условие по таблице предпочтений: any — есть хотя бы одно
+// из значений, all — есть все значения
+func (q *searchQuery) addPreference(t preferenceTable, ids pq.Int64Array, match string) {
+	q.args = append(q.args, ids)
+	n := len(q.args)
+	if match == models.MatchAll {
+		q.where = append(q.where, fmt.Sprintf(
+			"(SELECT count(*) FROM %s p WHERE p.user_id = u.id AND p.%s = ANY($%d)) = cardinality($%d::int[])",
+			t.table, t.column, n, n))
+		return
+	}
+	q.where = append(q.where, fmt.Sprintf(
+		"EXISTS (SELECT 1 FROM %s p WHERE p.user_id = u.id AND p.%s = ANY($%d))", t.table, t.column, n))
+}
+
+func (q *searchQuery) sql() string {
+	snippet := "''"
+	if q.text {
+		snippet = snippetExpr
+	}
+	query := fmt.Sprintf(searchUsersSelect, q.sort.key, snippet) + q.join
+	query += "\n        WHERE " + strings.Join(append([]string{searchVisibleCond}, q.where...), " AND ")
+	return query + fmt.Sprintf("\n        ORDER BY %s LIMIT %d", q.order, q.limit)
+}
+
+// parseFilterIDs разбирает ID справочника из фильтра без повторов.
+// Пустой результат означает «любое значение»
+func parseFilterIDs(name string, values models.FilterValues) (pq.Int64Array, error) {
+	var ids pq.Int64Array
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || value == anyFilterValue {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("%w: фильтр %s = %q", ErrInvalidSearch, name, value)
+		}
+		if !slices.Contains(ids, int64(id)) {
+			ids = append(ids, int64(id))
+		}
+	}
+	return ids, nil
+}
+
+// buildSearchQuery строит параметризованный запрос страницы поиска.
+// Страница запрашивается на одну строку больше лимита, чтобы узнать о наличии следующей
+func buildSearchQuery(req models.SearchRequest) (*searchQuery, *searchCursor, error) {
+	spec, ok := searchSorts[req.Sort]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: порядок сортировки %q", ErrInvalidSearch, req.Sort)
+	}
+	cursor, err := decodeCursor(req.Cursor, req.Sort)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	q := &searchQuery{sort: spec, args: []interface{}{req.ViewerID}, limit: req.Limit + 1}
+	if req.ViewerID > 0 {
+		q.where = append(q.where, "u.id <> $1")
+	}
+
+	fd := req.Filter
+	if query := strings.TrimSpace(fd.Query); query != "" {
+		q.args = append(q.args, query)
+		q.join = fmt.Sprintf(textQueryJoin, len(q.args))
+		q.text = true
+		q.where = append(q.where, "u.description_tsv @@ fts.query")
+	} else if req.Sort == models.SortRelevance {
+		return nil, nil, fmt.Errorf("%w: сортировка по релевантности без текстового запроса", ErrInvalidSearch)
+	}
+	// Игроки со скрытым возрастом не должны выдавать его попаданием в фильтр
+	if fd.Age0 > 0 || fd.Age1 > 0 {
+		q.where = append(q.where, "NOT u.hide_age")
+	}
+	if fd.Age0 > 0 {
+		q.add("u.age >= $%d", fd.Age0)
+	}
+	if fd.Age1 > 0 {
+		q.add("u.age <= $%d", fd.Age1)
+	}
+
+	match := fd.Match
+	if match == "" {
+		match = models.MatchAny
+	}
+	if match != models.MatchAny && match != models.MatchAll {
+		return nil, nil, fmt.Errorf("%w: режим совпадения %q", ErrInvalidSearch, fd.Match)
+	}
+	filters := map[string]models.FilterValues{
+		"game":     fd.Game,
+		"genre":    fd.Genre,
+		"language": fd.Language,
+		"app":      fd.App,
+	}
+	for _, t := range preferenceTables {
+		ids, err := parseFilterIDs(t.name, filters[t.name])
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(ids) > 0 {
+			q.addPreference(t, ids, match)
+		}
+	}
+
+	// При движении назад порядок разворачивается, а строки переворачиваются после выборки
+	desc := spec.desc
+	if cursor != nil && cursor.Dir == cursorPrev {
+		desc = !desc
+	}
+	dir, cmp := "ASC", ">"
+	if desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	if cursor != nil {
+		var key interface{} = cursor.Key
+		if spec.integer {
+			key = int64(cursor.Key)
+		}
+		q.args = append(q.args, key, cursor.ID)
+		q.where = append(q.where, fmt.Sprintf("((%s), u.id) %s ($%d, $%d)", spec.key, cmp, len(q.args)-1, len(q.args)))
+	}
+	q.order = fmt.Sprintf("(%s) %s, u.id %s", spec.key, dir, dir)
+
+	return q, cursor, nil
+}
+
+// SearchUsers возвращает страницу пользователей, подходящих под фильтр поиска
+func (pg *PGstorage) SearchUsers(ctx context.Context, req models.SearchRequest) (*models.SearchPage, error) {
+	defer metrics.ObserveDBQuery("SearchUsers")()
+	q, cursor, err := buildSearchQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := pg.DB.QueryContext(ctx, q.sql(), q.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type row struct {
+		id   int
+		key  float64
+		user models.UserListShow
+	}
+	var found []row
+	for rows.Next() {
+		var r row
+		var snippet string
+		if err := rows.Scan(&r.id, &r.key, &r.user.Username, &r.user.Age, &r.user.Description, &snippet, &r.user.Popularity, &r.user.MostLikeGame, &r.user.MostLikeGenre, &r.user.App, &r.user.Language); err != nil {
+			return nil, err
+		}
+		if q.text {
+			r.user.Snippet = parseSnippet(snippet)
+		}
+		found = append(found, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	more := len(found) > req.Limit
+	if more {
+		found = found[:req.Limit]
+	}
+	backward := cursor != nil && cursor.Dir == cursorPrev
+	if backward {
+		for i, j := 0, len(found)-1; i < j; i, j = i+1, j-1 {
+			found[i], found[j] = found[j], found[i]
+		}
+	}
+
+	page := &models.SearchPage{Users: make([]models.UserListShow, 0, len(found))}
+	for _, r := range found {
+		page.Users = append(page.Users, r.user)
+	}
+	if len(found) == 0 {
+		return page, nil
+	}
+
+	hasNext, hasPrev := more, cursor != nil
+	if backward {
+		hasNext, hasPrev = true, more
+	}
+	if hasNext {
+		last := found[len(found)-1]
+		page.NextCursor = encodeCursor(searchCursor{Sort: req.Sort, Key: last.key, ID: last.id, Dir: cursorNext})
+	}
+	if hasPrev {
+		first := found[0]
+		page.PrevCursor = encodeCursor(searchCursor{Sort: req.Sort, Key: first.key, ID: first.id, Dir: cursorPrev})
+	}
+
+	return page, nil
+}
+
+// parseSnippet разбирает результат ts_headline на обычный текст и подсвеченные слова
+func parseSnippet(headline string) []models.TextFragment {
+	var fragments []models.TextFragment
+	add := func(text string, match bool) {
+		if text != "" {
+			fragments = append(fragments, models.TextFragment{Text: text, Match: match})
+		}
+	}
+	for i, part := range strings.Split(headline, snippetStartSel) {
+		if i == 0 {
+			add(part, false)
+			continue
+		}
+		match, rest, _ := strings.Cut(part, snippetStopSel)
+		add(match, true)
+		add(rest, false)
+	}
+	return fragments
+}
@@ -0,0 +1,196 @@
+package pgstorage
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+type SearchQuerySuite struct {
+	suite.Suite
+}
+
+func TestSearchQuerySuite(t *testing.T) {
+	suite.Run(t, new(SearchQuerySuite))
+}
+
+func (s *SearchQuerySuite) TestAnyValues_NoConditions() {
+	q, _, err := buildSearchQuery(models.SearchRequest{
+		Filter: models.FilterData{Game: models.FilterValues{"-1"}, Genre: models.FilterValues{"-1"}, Language: models.FilterValues{"-1"}, App: models.FilterValues{""}},
+		Sort:   models.SortNewest,
+		Limit:  20,
+	})
+
+	s.NoError(err)
+	s.Empty(q.where)
+	s.Equal([]interface{}{0}, q.args)
+	s.Contains(q.sql(), "ORDER BY (u.id) DESC, u.id DESC LIMIT 21")
+}
+
+func (s *SearchQuerySuite) TestAllFields_Parameterized() {
+	q, _, err := buildSearchQuery(models.SearchRequest{
+		Filter: models.FilterData{
+			Age0:     18,
+			Age1:     30,
+			Game:     models.FilterValues{"2", "4", "2"},
+			Genre:    models.FilterValues{"5"},
+			Language: models.FilterValues{"1"},
+			App:      models.FilterValues{"3"},
+		},
+		Sort:     models.SortAge,
+		Limit:    10,
+		ViewerID: 7,
+	})
+
+	s.NoError(err)
+	s.Equal([]interface{}{7, 18, 30, pq.Int64Array{2, 4}, pq.Int64Array{5}, pq.Int64Array{1}, pq.Int64Array{3}}, q.args)
+	s.Equal([]string{
+		"u.id <> $1",
+		"NOT u.hide_age",
+		"u.age >= $2",
+		"u.age <= $3",
+		"EXISTS (SELECT 1 FROM user_games p WHERE p.user_id = u.id AND p.game_id = ANY($4))",
+		"EXISTS (SELECT 1 FROM user_genres p WHERE p.user_id = u.id AND p.genre_id = ANY($5))",
+		"EXISTS (SELECT 1 FROM user_languages p WHERE p.user_id = u.id AND p.language_id = ANY($6))",
+		"EXISTS (SELECT 1 FROM user_apps p WHERE p.user_id = u.id AND p.app_id = ANY($7))",
+	}, q.where)
+}
+
+func (s *SearchQuerySuite) TestHiddenProfilesExcluded() {
+	q, _, err := buildSearchQuery(models.SearchRequest{Sort: models.SortNewest, Limit: 20})
+
+	s.NoError(err)
+	s.Contains(q.sql(), "WHERE NOT u.hide_profile\n")
+}
+
+func (s *SearchQuerySuite) TestHiddenAgeRedacted() {
+	q, _, err := buildSearchQuery(models.SearchRequest{Filter: models.FilterData{Age1: 30}, Sort: models.SortAge, Limit: 20})
+
+	s.NoError(err)
+	s.Equal([]string{"NOT u.hide_age", "u.age <= $2"}, q.where)
+	sql := q.sql()
+	s.Contains(sql, "CASE WHEN u.hide_age THEN 0 ELSE COALESCE(u.age, 0) END AS age")
+	s.Contains(sql, "CASE WHEN u.hide_popularity THEN 0 ELSE u.popularity END AS popularity")
+	s.Contains(sql, "ORDER BY (CASE WHEN u.hide_age THEN 0 ELSE COALESCE(u.age, 0) END) ASC")
+}
+
+func (s *SearchQuerySuite) TestBestMatch_CountsSharedPreferences() {
+	q, _, err := buildSearchQuery(models.SearchRequest{Sort: models.SortBestMatch, Limit: 20, ViewerID: 7})
+
+	s.NoError(err)
+	sql := q.sql()
+	for _, t := range preferenceTables {
+		s.Contains(sql, "(SELECT count(*) FROM "+t.table+" p JOIN "+t.table+" m ON m."+t.column+" = p."+t.column+
+			" AND m.user_id = $1 WHERE p.user_id = u.id)")
+	}
+	s.NotContains(sql, "me.most_like_game")
+	s.Contains(sql, "ORDER BY ((SELECT count(*) FROM user_games p")
+}
+
+func (s *SearchQuerySuite) TestMatchAll_CountsSelectedValues() {
+	q, _, err := buildSearchQuery(models.SearchRequest{
+		Filter: models.FilterData{Language: models.FilterValues{"1", "2"}, Match: models.MatchAll},
+		Sort:   models.SortNewest,
+	})
+
+	s.NoError(err)
+	s.Equal([]interface{}{0, pq.Int64Array{1, 2}}, q.args)
+	s.Equal([]string{
+		"(SELECT count(*) FROM user_languages p WHERE p.user_id = u.id AND p.language_id = ANY($2)) = cardinality($2::int[])",
+	}, q.where)
+}
+
+func (s *SearchQuerySuite) TestUnknownMatchRejected() {
+	_, _, err := buildSearchQuery(models.SearchRequest{Filter: models.FilterData{Match: "some"}, Sort: models.SortNewest})
+
+	s.ErrorIs(err, ErrInvalidSearch)
+}
+
+func (s *SearchQuerySuite) TestInjectionRejected() {
+	_, _, err := buildSearchQuery(models.SearchRequest{Filter: models.FilterData{Genre: models.FilterValues{"1 OR 1=1"}}, Sort: models.SortNewest})
+
+	s.ErrorIs(err, ErrInvalidSearch)
+}
+
+func (s *SearchQuerySuite) TestNegativeIDRejected() {
+	_, _, err := buildSearchQuery(models.SearchRequest{Filter: models.FilterData{App: models.FilterValues{"-5"}}, Sort: models.SortNewest})
+
+	s.Error(err)
+}
+
+func (s *SearchQuerySuite) TestUnknownSortRejected() {
+	_, _, err := buildSearchQuery(models.SearchRequest{Sort: "random"})
+
+	s.ErrorIs(err, ErrInvalidSearch)
+}
+
+func (s *SearchQuerySuite) TestNextCursor_Keyset() {
+	cursor := encodeCursor(searchCursor{Sort: models.SortAge, Key: 25, ID: 12, Dir: cursorNext})
+
+	q, _, err := buildSearchQuery(models.SearchRequest{Sort: models.SortAge, Cursor: cursor, Limit: 5})
+
+	s.NoError(err)
+	s.Equal([]interface{}{0, int64(25), 12}, q.args)
+	s.Contains(q.where, "(("+publicAgeExpr+"), u.id) > ($2, $3)")
+	s.Contains(q.sql(), "ORDER BY ("+publicAgeExpr+") ASC, u.id ASC LIMIT 6")
+}
+
+func (s *SearchQuerySuite) TestPrevCursor_ReversesOrder() {
+	cursor := encodeCursor(searchCursor{Sort: models.SortPopularity, Key: 3.5, ID: 4, Dir: cursorPrev})
+
+	q, _, err := buildSearchQuery(models.SearchRequest{Sort: models.SortPopularity, Cursor: cursor, Limit: 5})
+
+	s.NoError(err)
+	s.Equal([]interface{}{0, 3.5, 4}, q.args)
+	s.Contains(q.where, "(("+publicPopularityExpr+"), u.id) > ($2, $3)")
+	s.Contains(q.sql(), "ORDER BY ("+publicPopularityExpr+") ASC, u.id ASC")
+}
+
+func (s *SearchQuerySuite) TestCursorForOtherSortRejected() {
+	cursor := encodeCursor(searchCursor{Sort: models.SortAge, Key: 25, ID: 12, Dir: cursorNext})
+
+	_, _, err := buildSearchQuery(models.SearchRequest{Sort: models.SortNewest, Cursor: cursor})
+
+	s.ErrorIs(err, ErrInvalidCursor)
+}
+
+func (s *SearchQuerySuite) TestGarbageCursorRejected() {
+	_, _, err := buildSearchQuery(models.SearchRequest{Sort: models.SortNewest, Cursor: "%%%"})
+
+	s.ErrorIs(err, ErrInvalidCursor)
+}
+
+func (s *SearchQuerySuite) TestTextQuery_RelevanceAndSnippet() {
+	q, _, err := buildSearchQuery(models.SearchRequest{
+		Filter:   models.FilterData{Query: " ranked evenings mic ", Game: models.FilterValues{"2"}},
+		Sort:     models.SortRelevance,
+		Limit:    10,
+		ViewerID: 7,
+	})
+
+	s.NoError(err)
+	s.Equal([]interface{}{7, "ranked evenings mic", pq.Int64Array{2}}, q.args)
+	s.Contains(q.where, "u.description_tsv @@ fts.query")
+	sql := q.sql()
+	s.Contains(sql, "websearch_to_tsquery('russian', $2) || websearch_to_tsquery('english', $2)")
+	s.Contains(sql, "ts_headline(")
+	s.Contains(sql, "ORDER BY (ts_rank(u.description_tsv, fts.query)) DESC, u.id DESC")
+}
+
+func (s *SearchQuerySuite) TestRelevanceWithoutQueryRejected() {
+	_, _, err := buildSearchQuery(models.SearchRequest{Sort: models.SortRelevance, Limit: 10})
+
+	s.ErrorIs(err, ErrInvalidSearch)
+}
+
+func (s *SearchQuerySuite) TestParseSnippet() {
+	s.Equal([]models.TextFragment{
+		{Text: "Играю "},
+		{Text: "ranked", Match: true},
+		{Text: " по вечерам, есть "},
+		{Text: "mic", Match: true},
+	}, parseSnippet("Играю \x01ranked\x02 по вечерам, есть \x01mic\x02"))
+}
@@ -0,0 +1,220 @@
+package pgstorage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DmitriySama/teammate_search/internal/apperr"
+	"github.com/DmitriySama/teammate_search/internal/metrics"
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+// Ошибки заявок в тиммейты
+var (
+	ErrTeammateRequestExists   = apperr.Conflict("заявка этому игроку уже отправлена")
+	ErrIncomingTeammateRequest = apperr.Conflict("игрок уже отправил вам заявку, примите её")
+	ErrAlreadyTeammates        = apperr.Conflict("вы уже тиммейты")
+	ErrTeammateRequestNotFound = apperr.NotFound("заявка не найдена")
+	ErrNotTeammates            = apperr.NotFound("игрок не в ваших тиммейтах")
+)
+
+// SendTeammateRequest создаёт заявку from -> to. Если пару уже связывает заявка
+// в любую сторону или игроки уже тиммейты, возвращается ошибка конфликта
+func (pg *PGstorage) SendTeammateRequest(ctx context.Context, from, to models.TeammateRef) error {
+	defer metrics.ObserveDBQuery("SendTeammateRequest")()
+	return pg.changeTeammates(ctx, models.TeammateActionRequest, from, to, func(tx *sql.Tx) error {
+		status, err := teammateStatus(ctx, tx, from.ID, to.ID)
+		if err != nil {
+			return err
+		}
+		switch status {
+		case models.TeammateStatusOutgoing:
+			return ErrTeammateRequestExists
+		case models.TeammateStatusIncoming:
+			return ErrIncomingTeammateRequest
+		case models.TeammateStatusTeammates:
+			return ErrAlreadyTeammates
+		}
+		// Встречная заявка, созданная параллельно, упрётся в уникальный индекс пары
+		return execOne(ctx, tx, ErrTeammateRequestExists, `
+			INSERT INTO friendships (requester_id, addressee_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING`, from.ID, to.ID)
+	})
+}
+
+// AcceptTeammateRequest принимает заявку, которую from отправил user
+func (pg *PGstorage) AcceptTeammateRequest(ctx context.Context, user, from models.TeammateRef) error {
+	defer metrics.ObserveDBQuery("AcceptTeammateRequest")()
+	return pg.changeTeammates(ctx, models.TeammateActionAccept, user, from, func(tx *sql.Tx) error {
+		return execOne(ctx, tx, ErrTeammateRequestNotFound, `
+			UPDATE friendships
+			SET status = 'accepted', updated_at = now()
+			WHERE requester_id = $1 AND addressee_id = $2 AND status = 'pending'`, from.ID, user.ID)
+	})
+}
+
+// DeclineTeammateRequest отклоняет заявку, которую from отправил user
+func (pg *PGstorage) DeclineTeammateRequest(ctx context.Context, user, from models.TeammateRef) error {
+	defer metrics.ObserveDBQuery("DeclineTeammateRequest")()
+	return pg.changeTeammates(ctx, models.TeammateActionDecline, user, from, func(tx *sql.Tx) error {
+		return execOne(ctx, tx, ErrTeammateRequestNotFound, `
+			DELETE FROM friendships
+			WHERE requester_id = $1 AND addressee_id = $2 AND status = 'pending'`, from.ID, user.ID)
+	})
+}
+
+// CancelTeammateRequest отзывает заявку, которую user отправил to
+func (pg *PGstorage) CancelTeammateRequest(ctx context.Context, user, to models.TeammateRef) error {
+	defer metrics.ObserveDBQuery("CancelTeammateRequest")()
+	return pg.changeTeammates(ctx, models.TeammateActionCancel, user, to, func(tx *sql.Tx) error {
+		return execOne(ctx, tx, ErrTeammateRequestNotFound, `
+			DELETE FROM friendships
+			WHERE requester_id = $1 AND addressee_id = $2 AND status = 'pending'`, user.ID, to.ID)
+	})
+}
+
+// RemoveTeammate удаляет other из тиммейтов user независимо от того, кто отправлял заявку
+func (pg *PGstorage) RemoveTeammate(ctx context.Context, user, other models.TeammateRef) error {
+	defer metrics.ObserveDBQuery("RemoveTeammate")()
+	return pg.changeTeammates(ctx, models.TeammateActionRemove, user, other, func(tx *sql.Tx) error {
+		return execOne(ctx, tx, ErrNotTeammates, `
+			DELETE FROM friendships
+			WHERE ((requester_id = $1 AND addressee_id = $2) OR (requester_id = $2 AND addressee_id = $1))
+			  AND status = 'accepted'`, user.ID, other.ID)
+	})
+}
+
+// TeammateStatus состояние связи userID с otherID с точки зрения userID
+func (pg *PGstorage) TeammateStatus(ctx context.Context, userID, otherID int) (string, error) {
+	defer metrics.ObserveDBQuery("TeammateStatus")()
+	return teammateStatus(ctx, pg.DB, userID, otherID)
+}
+
+// ListTeammates возвращает входящие и исходящие заявки и тиммейтов пользователя,
+// в каждом списке до limit самых новых записей
+func (pg *PGstorage) ListTeammates(ctx context.Context, userID, limit int) (*models.TeammateLists, error) {
+	defer metrics.ObserveDBQuery("ListTeammates")()
+	rows, err := pg.DB.QueryContext(ctx, `
+		SELECT username, status, outgoing, updated_at
+		FROM (
+			SELECT u.username, f.status, f.requester_id = $1 AS outgoing, f.updated_at,
+			       row_number() OVER (
+			           PARTITION BY CASE WHEN f.status = 'accepted' THEN 0 WHEN f.requester_id = $1 THEN 1 ELSE 2 END
+			           ORDER BY f.updated_at DESC, u.username) AS n
+			FROM friendships f
+			JOIN users u ON u.id = CASE WHEN f.requester_id = $1 THEN f.addressee_id ELSE f.requester_id END
+			WHERE f.requester_id = $1 OR f.addressee_id = $1
+		) t
+		WHERE n <= $2
+		ORDER BY updated_at DESC, username`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists := &models.TeammateLists{
+		Incoming:  []models.Teammate{},
+		Outgoing:  []models.Teammate{},
+		Teammates: []models.Teammate{},
+	}
+	for rows.Next() {
+		var t models.Teammate
+		var status string
+		var outgoing bool
+		if err := rows.Scan(&t.Username, &status, &outgoing, &t.Since); err != nil {
+			return nil, err
+		}
+		switch {
+		case status == friendshipAccepted:
+			lists.Teammates = append(lists.Teammates, t)
+		case outgoing:
+			lists.Outgoing = append(lists.Outgoing, t)
+		default:
+			lists.Incoming = append(lists.Incoming, t)
+		}
+	}
+	return lists, rows.Err()
+}
+
+// friendshipAccepted значение friendships.status принятой заявки
+const friendshipAccepted = "accepted"
+
+// teammateStatus читает связь пары игроков и переводит её в состояние с точки зрения userID
+func teammateStatus(ctx context.Context, q querier, userID, otherID int) (string, error) {
+	var requesterID int
+	var status string
+	err := q.QueryRowContext(ctx, `
+		SELECT requester_id, status
+		FROM friendships
+		WHERE (requester_id = $1 AND addressee_id = $2) OR (requester_id = $2 AND addressee_id = $1)`,
+		userID, otherID).Scan(&requesterID, &status)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return models.TeammateStatusNone, nil
+	case err != nil:
+		return "", err
+	case status == friendshipAccepted:
+		return models.TeammateStatusTeammates, nil
+	case requesterID == userID:
+		return models.TeammateStatusOutgoing, nil
+	default:
+		return models.TeammateStatusIncoming, nil
+	}
+}
+
+// changeTeammates выполняет change в транзакции и ставит в outbox событие TeammateEvent:
+// actor выполнил action, target — второй игрок пары
+func (pg *PGstorage) changeTeammates(ctx context.Context, action string, actor, target models.TeammateRef, change func(tx *sql.Tx) error) error {
+	tx, err := pg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := change(tx); err != nil {
+		return err
+	}
+	data, err := json.Marshal(models.TeammateEvent{
+		Version: models.TeammateEventVersion,
+		Action:  action,
+		Actor:   actor,
+		Target:  target,
+		At:      time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := enqueueEvent(ctx, tx, pg.topics.TeammateRequests, teammatePairKey(actor.ID, target.ID), data); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// teammatePairKey ключ сообщения пары игроков, одинаковый при любом порядке ID
+func teammatePairKey(a, b int) string {
+	if a > b {
+		a, b = b, a
+	}
+	return fmt.Sprintf("%d:%d", a, b)
+}
+
+// execOne выполняет запрос и возвращает notFound, если он не затронул ни одной строки
+func execOne(ctx context.Context, tx *sql.Tx, notFound error, query string, args ...any) error {
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package pgstorage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+type TeammatesSuite struct {
+	suite.Suite
+	ctx  context.Context
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	pg   *PGstorage
+}
+
+func (s *TeammatesSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+	s.ctx = context.Background()
+	s.db, s.mock = db, mock
+	s.pg = &PGstorage{DB: db, topics: testTopics}
+}
+
+func (s *TeammatesSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+func TestTeammatesSuite(t *testing.T) {
+	suite.Run(t, new(TeammatesSuite))
+}
+
+// teammateEvent проверяет, что аргумент — JSON события TeammateEvent с нужным действием и участниками
+type teammateEvent struct {
+	action        string
+	actor, target int
+}
+
+func (e teammateEvent) Match(v driver.Value) bool {
+	data, ok := v.([]byte)
+	if !ok {
+		return false
+	}
+	var ev models.TeammateEvent
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return false
+	}
+	return ev.Version == models.TeammateEventVersion && ev.Action == e.action &&
+		ev.Actor.ID == e.actor && ev.Target.ID == e.target
+}
+
+var (
+	teammateMe    = models.TeammateRef{ID: 7, Username: "me"}
+	teammateOther = models.TeammateRef{ID: 3, Username: "player"}
+)
+
+func (s *TeammatesSuite) TestSendTeammateRequest_EnqueuesEvent() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta("FROM friendships")).WithArgs(7, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"requester_id", "status"}))
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO friendships")).WithArgs(7, 3).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox")).
+		WithArgs(testTopics.TeammateRequests, "3:7", teammateEvent{action: models.TeammateActionRequest, actor: 7, target: 3}, "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.pg.SendTeammateRequest(s.ctx, teammateMe, teammateOther)
+
+	s.NoError(err)
+}
+
+func (s *TeammatesSuite) TestAcceptTeammateRequest_SamePairKey() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE friendships")).WithArgs(7, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox")).
+		WithArgs(testTopics.TeammateRequests, "3:7", teammateEvent{action: models.TeammateActionAccept, actor: 3, target: 7}, "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.pg.AcceptTeammateRequest(s.ctx, teammateOther, teammateMe)
+
+	s.NoError(err)
+}
+
+func (s *TeammatesSuite) TestSendTeammateRequest_IncomingExists() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta("FROM friendships")).WithArgs(7, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"requester_id", "status"}).AddRow(3, "pending"))
+	s.mock.ExpectRollback()
+
+	err := s.pg.SendTeammateRequest(s.ctx, teammateMe, teammateOther)
+
+	s.ErrorIs(err, ErrIncomingTeammateRequest)
+}
+
+func (s *TeammatesSuite) TestSendTeammateRequest_ConcurrentDuplicate() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta("FROM friendships")).WithArgs(7, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"requester_id", "status"}))
+	s.mock.ExpectExec(regexp.QuoteMeta("ON CONFLICT DO NOTHING")).WithArgs(7, 3).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectRollback()
+
+	err := s.pg.SendTeammateRequest(s.ctx, teammateMe, teammateOther)
+
+	s.ErrorIs(err, ErrTeammateRequestExists)
+}
+
+func (s *TeammatesSuite) TestAcceptTeammateRequest_NotFoundNoEvent() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("UPDATE friendships")).WithArgs(3, 7).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectRollback()
+
+	err := s.pg.AcceptTeammateRequest(s.ctx, teammateMe, teammateOther)
+
+	s.ErrorIs(err, ErrTeammateRequestNotFound)
+}
+
+func (s *TeammatesSuite) TestRemoveTeammate_EnqueuesEvent() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("DELETE FROM friendships")).WithArgs(7, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox")).
+		WithArgs(testTopics.TeammateRequests, "3:7", teammateEvent{action: models.TeammateActionRemove, actor: 7, target: 3}, "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.pg.RemoveTeammate(s.ctx, teammateMe, teammateOther)
+
+	s.NoError(err)
+}
+
+func (s *TeammatesSuite) TestListTeammates_SplitsByStatus() {
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	s.mock.ExpectQuery(regexp.QuoteMeta("WHERE n <= $2")).WithArgs(7, 50).
+		WillReturnRows(sqlmock.NewRows([]string{"username", "status", "outgoing", "updated_at"}).
+			AddRow("friend", "accepted", true, at).
+			AddRow("asker", "pending", false, at).
+			AddRow("asked", "pending", true, at))
+
+	lists, err := s.pg.ListTeammates(s.ctx, 7, 50)
+
+	s.Require().NoError(err)
+	s.Equal([]models.Teammate{{Username: "friend", Since: at}}, lists.Teammates)
+	s.Equal([]models.Teammate{{Username: "asker", Since: at}}, lists.Incoming)
+	s.Equal([]models.Teammate{{Username: "asked", Since: at}}, lists.Outgoing)
+}
+
+func (s *TeammatesSuite) TestTeammatePairKey_OrderIndependent() {
+	s.Equal("3:7", teammatePairKey(3, 7))
+	s.Equal("3:7", teammatePairKey(7, 3))
+	s.Equal("5:5", teammatePairKey(5, 5))
+}
@@ -0,0 +1,87 @@
+package pgstorage
+
+import (
+	"context"
+	"time"
+
+	"github.com/DmitriySama/teammate_search/internal/metrics"
+	"github.com/DmitriySama/teammate_search/internal/models"
+)
+
+// AddSearchTrends прибавляет счётчики к агрегатам поисковых запросов
+func (pg *PGstorage) AddSearchTrends(ctx context.Context, counts map[models.TrendBucket]int) error {
+    defer metrics.ObserveDBQuery("AddSearchTrends")()
+    tx, err := pg.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    stmt, err := tx.PrepareContext(ctx, `
+        INSERT INTO search_trends (granularity, bucket_start, dimension, value, count)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (granularity, bucket_start, dimension, value)
+        DO UPDATE SET count = search_trends.count + EXCLUDED.count
+    `)
+    if err != nil {
+        return err
+    }
+    defer stmt.Close()
+
+    for bucket, count := range counts {
+        if _, err := stmt.ExecContext(ctx, bucket.Granularity, bucket.Start, bucket.Dimension, bucket.Value, count); err != nil {
+            return err
+        }
+    }
+    return tx.Commit()
+}
+
+// GetTrending возвращает до limit самых частых значений каждого измерения с момента since.
+// Для справочников подставляется название, для диапазонов возраста — само значение
+func (pg *PGstorage) GetTrending(ctx context.Context, granularity string, since time.Time, limit int) (*models.Trending, error) {
+    defer metrics.ObserveDBQuery("GetTrending")()
+    rows, err := pg.DB.QueryContext(ctx, `
+        WITH totals AS (
+            SELECT dimension, value, SUM(count) AS total
+            FROM search_trends
+            WHERE granularity = $1 AND bucket_start >= $2
+            GROUP BY dimension, value
+        ), ranked AS (
+            SELECT dimension, value, total,
+                ROW_NUMBER() OVER (PARTITION BY dimension ORDER BY total DESC, value) AS place
+            FROM totals
+        )
+        SELECT 
+            r.dimension,
+            r.value,
+            COALESCE(g1.game, g.genre, l.language, a.app, r.value) AS label,
+            r.total
+        FROM ranked r
+        LEFT JOIN games g1 ON r.dimension = 'game' AND g1.id_game::text = r.value
+        LEFT JOIN genres g ON r.dimension = 'genre' AND g.id_genre::text = r.value
+        LEFT JOIN languages l ON r.dimension = 'language' AND l.id_language::text = r.value
+        LEFT JOIN apps a ON r.dimension = 'app' AND a.id_app::text = r.value
+        WHERE r.place <= $3
+        ORDER BY r.dimension, r.place
+    `, granularity, since, limit)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    trending := &models.Trending{
+        Granularity: granularity,
+        Since: since,
+        Items: make(map[string][]models.TrendItem),
+    }
+    for rows.Next() {
+        var dimension string
+        var item models.TrendItem
+        if err := rows.Scan(&dimension, &item.Value, &item.Label, &item.Count); err != nil {
+            return nil, err
+        }
+        trending.Items[dimension] = append(trending.Items[dimension], item)
+    }
+    
+    return trending, rows.Err()
+}